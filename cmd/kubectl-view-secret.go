@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/elsesiy/kubectl-view-secret/pkg/cmd"
@@ -9,6 +10,10 @@ import (
 func main() {
 	command := cmd.NewCmdViewSecret()
 	if err := command.Execute(); err != nil {
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// encodingBase64 marks values which are base64 encoded because they contain binary data
+const encodingBase64 = "base64"
+
+// ErrRawRequiresSingleKey is thrown if --raw is used without selecting exactly one key
+var ErrRawRequiresSingleKey = errors.New("--raw requires a single key, specify the key to write")
+
+// isBinary reports whether a value can't be printed to a terminal as is
+//
+// Values which aren't valid UTF-8 or contain control characters other than
+// whitespace are considered binary.
+func isBinary(value string) bool {
+	if !utf8.ValidString(value) {
+		return true
+	}
+
+	for _, r := range value {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newKeyValue creates the output representation of a decoded value
+//
+// Binary values are base64 encoded so they survive the json and yaml output.
+func newKeyValue(key, value string) KeyValue {
+	if isBinary(value) {
+		return KeyValue{Encoding: encodingBase64, Key: key, Value: base64.StdEncoding.EncodeToString([]byte(value))}
+	}
+	return KeyValue{Key: key, Value: value}
+}
+
+// writeHexdump outputs a binary value in the format of `hexdump -C`
+func writeHexdump(outWriter io.Writer, kv KeyValue) error {
+	raw, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		return fmt.Errorf("failed to decode binary value of key %s: %w", kv.Key, err)
+	}
+
+	if _, err := io.WriteString(outWriter, hex.Dump(raw)); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// decodeRawValue decodes a value to its exact bytes, applying the value transformations selected in the options
+func decodeRawValue(secret Secret, key string, opts ProcessOptions) ([]byte, error) {
+	v, ok := secret.Data[key]
+	if !ok {
+		return nil, ErrSecretKeyNotFound
+	}
+
+	kv, err := decodeKeyValue(secret, key, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if kv.Encoding != encodingBase64 {
+		return []byte(kv.Value), nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode binary value of key %s: %w", key, err)
+	}
+
+	return raw, nil
+}
+
+// writeRaw outputs the exact bytes of the decoded value without a trailing newline
+func writeRaw(outWriter io.Writer, secret Secret, key string, opts ProcessOptions) error {
+	raw, err := decodeRawValue(secret, key, opts)
+	if err != nil {
+		return err
+	}
+
+	if _, err := outWriter.Write(raw); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		want  bool
+	}{
+		"text":          {"secret", false},
+		"multiline":     {"line1\r\nline2\ttabbed\n", false},
+		"unicode":       {"pässwörd 🔑", false},
+		"empty":         {"", false},
+		"invalid utf-8": {"\xff\xfe\xfd", true},
+		"nul byte":      {"abc\x00def", true},
+		"escape":        {"\x1b[2J", true},
+		"gzip header":   {"\x1f\x8b\x08\x00", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, isBinary(tt.value))
+		})
+	}
+}
+
+func TestProcessSecretBinary(t *testing.T) {
+	binary := []byte{0xfe, 0xed, 0xfe, 0xed, 0x00, 0x00, 0x00, 0x02, 'j', 'k', 's'}
+	secret := Secret{
+		Data: SecretData{
+			"keystore.jks": base64.StdEncoding.EncodeToString(binary),
+			"password":     base64.StdEncoding.EncodeToString([]byte("changeit\n")),
+		},
+		Metadata: Metadata{Name: "keystore", Namespace: "default"},
+		Type:     Opaque,
+	}
+
+	tests := map[string]struct {
+		opts    ProcessOptions
+		want    string
+		wantErr error
+	}{
+		"text single key": {
+			opts: ProcessOptions{OutputFormat: "text", SecretKey: "keystore.jks"},
+			want: "00000000  fe ed fe ed 00 00 00 02  6a 6b 73                 |........jks|\n",
+		},
+		"text all keys": {
+			opts: ProcessOptions{DecodeAll: true, OutputFormat: "text"},
+			want: "keystore.jks (binary):\n" +
+				"00000000  fe ed fe ed 00 00 00 02  6a 6b 73                 |........jks|\n" +
+				"password='changeit\n'\n",
+		},
+		"json": {
+			opts: ProcessOptions{OutputFormat: "json", SecretKey: "keystore.jks"},
+			want: `{
+  "data": [
+    {
+      "encoding": "base64",
+      "key": "keystore.jks",
+      "value": "/u3+7QAAAAJqa3M="
+    }
+  ],
+  "name": "keystore",
+  "namespace": "default",
+  "type": "Opaque"
+}
+`,
+		},
+		"yaml": {
+			opts: ProcessOptions{DecodeAll: true, OutputFormat: "yaml"},
+			want: `data:
+    - encoding: base64
+      key: keystore.jks
+      value: /u3+7QAAAAJqa3M=
+    - key: password
+      value: |
+        changeit
+name: keystore
+namespace: default
+type: Opaque
+`,
+		},
+		"raw binary": {
+			opts: ProcessOptions{OutputFormat: "text", Raw: true, SecretKey: "keystore.jks"},
+			want: string(binary),
+		},
+		"raw text": {
+			opts: ProcessOptions{OutputFormat: "json", Raw: true, SecretKey: "password"},
+			want: "changeit\n",
+		},
+		"raw without key": {
+			opts:    ProcessOptions{OutputFormat: "text", Raw: true},
+			wantErr: ErrRawRequiresSingleKey,
+		},
+		"raw all keys": {
+			opts:    ProcessOptions{DecodeAll: true, OutputFormat: "text", Raw: true, SecretKey: "password"},
+			wantErr: ErrRawRequiresSingleKey,
+		},
+		"raw missing key": {
+			opts:    ProcessOptions{OutputFormat: "text", Raw: true, SecretKey: "missing"},
+			wantErr: ErrSecretKeyNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var outBuf, errBuf bytes.Buffer
+			err := ProcessSecretWithOptions(&outBuf, &errBuf, nil, secret, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+
+	t.Run("raw single key secret", func(t *testing.T) {
+		var outBuf, errBuf bytes.Buffer
+		single := Secret{Data: SecretData{"keystore.jks": secret.Data["keystore.jks"]}, Type: Opaque}
+		assert.NoError(t, ProcessSecretWithOptions(&outBuf, &errBuf, nil, single, ProcessOptions{Raw: true}))
+		assert.Equal(t, binary, outBuf.Bytes())
+	})
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Keys of bootstrap token secrets
+//
+// ref: https://kubernetes.io/docs/reference/access-authn-authz/bootstrap-tokens/#bootstrap-token-secret-format
+const (
+	bootstrapAuthExtraGroupsKey = "auth-extra-groups"
+	bootstrapDescriptionKey     = "description"
+	bootstrapExpirationKey      = "expiration"
+	bootstrapTokenIDKey         = "token-id"
+	bootstrapTokenSecretKey     = "token-secret"
+	bootstrapUsagePrefix        = "usage-bootstrap-"
+)
+
+// BootstrapTokenDetails represents the parsed view of a bootstrap.kubernetes.io/token secret
+type BootstrapTokenDetails struct {
+	AuthExtraGroups []string    `json:"authExtraGroups,omitempty" yaml:"authExtraGroups,omitempty"`
+	Description     string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Expiration      *time.Time  `json:"expiration,omitempty" yaml:"expiration,omitempty"`
+	Status          TokenStatus `json:"status" yaml:"status"`
+	Token           string      `json:"token" yaml:"token"`
+	TokenID         string      `json:"tokenID" yaml:"tokenID"`
+	Usages          []string    `json:"usages,omitempty" yaml:"usages,omitempty"`
+
+	// now is the reference time for the relative expiration in the text output
+	now time.Time
+}
+
+// parseBootstrapTokenDetails assembles the token and its metadata from a bootstrap token secret
+func parseBootstrapTokenDetails(secret Secret, opts DetailsOptions) (BootstrapTokenDetails, error) {
+	details := BootstrapTokenDetails{Status: TokenStatusValid, now: opts.Now}
+
+	values := map[string]string{}
+	for key := range secret.Data {
+		raw, err := decodeRawKey(secret, key)
+		if err != nil {
+			return details, err
+		}
+		values[key] = strings.TrimSpace(string(raw))
+	}
+
+	tokenID, ok := values[bootstrapTokenIDKey]
+	if !ok {
+		return details, fmt.Errorf("%w: %s", ErrSecretKeyNotFound, bootstrapTokenIDKey)
+	}
+	tokenSecret, ok := values[bootstrapTokenSecretKey]
+	if !ok {
+		return details, fmt.Errorf("%w: %s", ErrSecretKeyNotFound, bootstrapTokenSecretKey)
+	}
+
+	tokenSecret = opts.Mask.apply(tokenSecret)
+	details.TokenID = tokenID
+	details.Token = tokenID + "." + tokenSecret
+	details.Description = values[bootstrapDescriptionKey]
+
+	if expiration, ok := values[bootstrapExpirationKey]; ok {
+		t, err := time.Parse(time.RFC3339, expiration)
+		if err != nil {
+			return details, fmt.Errorf("failed to parse %s: %w", bootstrapExpirationKey, err)
+		}
+		t = t.UTC()
+		details.Expiration = &t
+		if !opts.Now.Before(t) {
+			details.Status = TokenStatusExpired
+		}
+	}
+
+	if groups := values[bootstrapAuthExtraGroupsKey]; groups != "" {
+		for _, g := range strings.Split(groups, ",") {
+			details.AuthExtraGroups = append(details.AuthExtraGroups, strings.TrimSpace(g))
+		}
+	}
+
+	for key, value := range values {
+		if usage, ok := strings.CutPrefix(key, bootstrapUsagePrefix); ok && value == "true" {
+			details.Usages = append(details.Usages, usage)
+		}
+	}
+	sort.Strings(details.Usages)
+
+	return details, nil
+}
+
+// renderText outputs the bootstrap token details in a human readable form
+func (d BootstrapTokenDetails) renderText(outWriter io.Writer) error {
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(w, "Token:\t%s\n", d.Token)
+	_, _ = fmt.Fprintf(w, "Status:\t%s\n", strings.ToUpper(string(d.Status)))
+	if d.Expiration != nil {
+		_, _ = fmt.Fprintf(w, "Expiration:\t%s\n", relativeTime(*d.Expiration, d.now))
+	} else {
+		_, _ = fmt.Fprintf(w, "Expiration:\tnever\n")
+	}
+	if d.Description != "" {
+		_, _ = fmt.Fprintf(w, "Description:\t%s\n", d.Description)
+	}
+	if len(d.Usages) > 0 {
+		_, _ = fmt.Fprintf(w, "Usages:\t%s\n", strings.Join(d.Usages, ", "))
+	} else {
+		_, _ = fmt.Fprintf(w, "Usages:\tnone\n")
+	}
+	if len(d.AuthExtraGroups) > 0 {
+		_, _ = fmt.Fprintf(w, "Auth Extra Groups:\t%s\n", strings.Join(d.AuthExtraGroups, ", "))
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBootstrapTokenDetails(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiration := time.Date(2025, 6, 2, 14, 30, 0, 0, time.UTC)
+	kubeadm := map[string]string{
+		"auth-extra-groups":              "system:bootstrappers:kubeadm:default-node-token",
+		"description":                    "kubeadm join token",
+		"expiration":                     "2025-06-02T16:30:00+02:00",
+		"token-id":                       "abcdef",
+		"token-secret":                   "0123456789abcdef",
+		"usage-bootstrap-authentication": "true",
+		"usage-bootstrap-signing":        "true",
+	}
+
+	tests := map[string]struct {
+		values  map[string]string
+		opts    DetailsOptions
+		want    BootstrapTokenDetails
+		wantErr error
+	}{
+		"kubeadm token": {
+			values: kubeadm,
+			opts:   DetailsOptions{Now: now},
+			want: BootstrapTokenDetails{
+				AuthExtraGroups: []string{"system:bootstrappers:kubeadm:default-node-token"},
+				Description:     "kubeadm join token",
+				Expiration:      &expiration,
+				Status:          TokenStatusValid,
+				Token:           "abcdef.0123456789abcdef",
+				TokenID:         "abcdef",
+				Usages:          []string{"authentication", "signing"},
+				now:             now,
+			},
+		},
+		"expired and masked": {
+			values: kubeadm,
+			opts:   DetailsOptions{Mask: Masking{Mode: MaskFull}, Now: expiration},
+			want: BootstrapTokenDetails{
+				AuthExtraGroups: []string{"system:bootstrappers:kubeadm:default-node-token"},
+				Description:     "kubeadm join token",
+				Expiration:      &expiration,
+				Status:          TokenStatusExpired,
+				Token:           "abcdef." + maskedValue,
+				TokenID:         "abcdef",
+				Usages:          []string{"authentication", "signing"},
+				now:             expiration,
+			},
+		},
+		"no expiration or usages": {
+			values: map[string]string{"token-id": "abcdef", "token-secret": "0123456789abcdef", "usage-bootstrap-signing": "false"},
+			opts:   DetailsOptions{Now: now},
+			want:   BootstrapTokenDetails{Status: TokenStatusValid, Token: "abcdef.0123456789abcdef", TokenID: "abcdef", now: now},
+		},
+		"missing secret": {
+			values:  map[string]string{"token-id": "abcdef"},
+			wantErr: ErrSecretKeyNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseBootstrapTokenDetails(newTestSecret(Token, "kube-system", "bootstrap-token-abcdef", tt.values), tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("text", func(t *testing.T) {
+		details, err := secretDetails(newTestSecret(Token, "kube-system", "bootstrap-token-abcdef", kubeadm), DetailsOptions{Now: now})
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, outputFormattedSecret(&buf, Secret{}, details, "text"))
+		assert.Equal(t, "Token:              abcdef.0123456789abcdef\n"+
+			"Status:             VALID\n"+
+			"Expiration:         2025-06-02T14:30:00Z (in 1d2h)\n"+
+			"Description:        kubeadm join token\n"+
+			"Usages:             authentication, signing\n"+
+			"Auth Extra Groups:  system:bootstrappers:kubeadm:default-node-token\n", buf.String())
+	})
+}
@@ -0,0 +1,1013 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	browseHelp     = "↑/↓ move • enter open • esc back • / filter • r reveal • c copy • e export • d diff • q quit"
+	browseFilterOn = "filter: %s█"
+
+	// defaultBrowseWidth and defaultBrowseHeight are used until the terminal reports its size
+	defaultBrowseWidth  = 100
+	defaultBrowseHeight = 30
+
+	// currentNamespaceLabel is shown for the namespace of the context if namespaces can't be listed
+	currentNamespaceLabel = "(current)"
+)
+
+var (
+	// ErrBrowseWithSecretName is thrown if --browse is combined with a secret name or selectors
+	ErrBrowseWithSecretName = errors.New("--browse navigates to the secret, don't specify a secret name, key or selector")
+
+	// ErrBrowseFromStdin is thrown if the manifests browsed should be read from stdin which is needed for the keyboard
+	ErrBrowseFromStdin = errors.New("--browse reads the keyboard from stdin, pass the manifests as files")
+)
+
+var (
+	browseDimStyle      = lipgloss.NewStyle().Faint(true)
+	browseErrorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	browsePaneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	browseSelectedStyle = lipgloss.NewStyle().Reverse(true)
+	browseTitleStyle    = lipgloss.NewStyle().Bold(true)
+)
+
+// browseLevel is a level of the secret browser hierarchy
+type browseLevel int
+
+const (
+	browseContexts browseLevel = iota
+	browseNamespaces
+	browseSecrets
+	browseKeys
+)
+
+// browseLevelTitles are the list titles per level
+var browseLevelTitles = map[browseLevel]string{
+	browseContexts:   "Contexts",
+	browseNamespaces: "Namespaces",
+	browseSecrets:    "Secrets",
+	browseKeys:       "Keys",
+}
+
+// browseBackend opens the secret sources navigated by the browser
+type browseBackend interface {
+	// contexts returns the kubeconfig contexts and the current one, none if secrets aren't read from a cluster
+	contexts() ([]string, string, error)
+
+	// source returns the secret source for the namespace in the context
+	source(kubeContext, namespace string) (SecretSource, error)
+}
+
+// clusterBrowseBackend browses the contexts of the kubeconfig
+type clusterBrowseBackend struct {
+	backend string
+	opts    SourceOptions
+}
+
+// contexts returns the context names of the kubeconfig using the loading rules of kubectl
+func (b clusterBrowseBackend) contexts() ([]string, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if b.opts.KubeConfig != "" {
+		rules.ExplicitPath = b.opts.KubeConfig
+	}
+
+	config, err := rules.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, config.CurrentContext, nil
+}
+
+// source creates a secret source for the namespace in the context, keeping the other connection settings
+func (b clusterBrowseBackend) source(kubeContext, namespace string) (SecretSource, error) {
+	opts := b.opts
+	if kubeContext != "" {
+		opts.Context = kubeContext
+	}
+	opts.Namespace = namespace
+
+	return NewSecretSource(b.backend, opts)
+}
+
+// manifestBrowseBackend browses the secrets read from manifests
+type manifestBrowseBackend struct {
+	secrets []Secret
+}
+
+// contexts returns no contexts since manifests aren't bound to a cluster
+func (manifestBrowseBackend) contexts() ([]string, string, error) {
+	return nil, "", nil
+}
+
+// source returns the secrets of the manifests in the namespace
+func (b manifestBrowseBackend) source(_, namespace string) (SecretSource, error) {
+	return &manifestSource{namespace: namespace, secrets: b.secrets}, nil
+}
+
+// browseItem is an entry of a browser list
+//
+// The value identifies the entry, the label is displayed and filtered.
+type browseItem struct {
+	detail string
+	label  string
+	value  string
+}
+
+// browseList is the list shown for one level of the hierarchy
+type browseList struct {
+	cursor  int
+	filter  string
+	items   []browseItem
+	level   browseLevel
+	matches []int
+}
+
+// browseLoadedMsg carries the entries of a level loaded in the background
+type browseLoadedMsg struct {
+	current string
+	err     error
+	items   []browseItem
+	level   browseLevel
+	secrets []Secret
+	seq     int
+}
+
+// browseClipboardClearMsg is sent once a copied value should be cleared from the clipboard
+type browseClipboardClearMsg struct {
+	value string
+}
+
+// browseModel is the bubbletea model of the full-screen secret browser
+//
+// Lists are stacked so navigating back restores the previous level with its
+// filter and cursor. Values are masked unless revealed.
+type browseModel struct {
+	backend        browseBackend
+	clipboard      Clipboard
+	clipboardClear time.Duration
+	clipboardErr   error
+	copied         string
+	ctx            context.Context
+	diffFrom       *Secret
+	diffRef        SecretRef
+	diffText       string
+	exportDir      string
+	exportForce    bool
+	filtering      bool
+	height         int
+	kubeContext    string
+	lists          []browseList
+	loading        bool
+	mask           Masking
+	namespace      string
+	now            func() time.Time
+	pending        []string
+	revealed       bool
+	secrets        map[string]Secret
+	seq            int
+	status         string
+	statusErr      bool
+	width          int
+}
+
+// newBrowseModel creates the browser starting at the contexts of the backend
+//
+// A context and namespace given on the command line are opened right away,
+// the levels above stay on the stack to navigate back.
+func newBrowseModel(backend browseBackend, mask Masking, kubeContext, namespace string) browseModel {
+	if mask.Mode == MaskNone {
+		mask.Mode = MaskFull
+	}
+
+	m := browseModel{
+		backend:   backend,
+		ctx:       context.Background(),
+		exportDir: ".",
+		height:    defaultBrowseHeight,
+		mask:      mask,
+		now:       time.Now,
+		secrets:   map[string]Secret{},
+		width:     defaultBrowseWidth,
+	}
+
+	if kubeContext != "" {
+		m.pending = append(m.pending, kubeContext)
+		if namespace != "" {
+			m.pending = append(m.pending, namespace)
+		}
+	} else if namespace != "" {
+		// without a context the namespace is opened in the current one
+		m.pending = append(m.pending, "", namespace)
+	}
+
+	return m
+}
+
+// runBrowser starts the full-screen browser on the terminal
+func (c *CommandOpts) runBrowser(cmd *cobra.Command) error {
+	if c.secretName != "" || c.selectsMultiple() {
+		return ErrBrowseWithSecretName
+	}
+
+	mask := Masking{Chars: c.maskChars, Mode: MaskMode(c.mask)}
+	if err := mask.validate(); err != nil {
+		return err
+	}
+
+	backend, err := c.browseBackend(cmd)
+	if err != nil {
+		return err
+	}
+
+	m := newBrowseModel(backend, mask, c.customContext, c.customNamespace)
+	m.ctx = contextFromCommand(cmd)
+	m.clipboard, m.clipboardErr = newClipboard(cmd.OutOrStdout())
+	m.clipboardClear = c.clipboardClear
+	if c.toDir != "" {
+		m.exportDir = c.toDir
+	}
+	m.exportForce = c.force
+
+	_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithInput(cmd.InOrStdin()), tea.WithOutput(cmd.OutOrStdout())).Run()
+	if err != nil {
+		return fmt.Errorf("failed to run browser: %w", err)
+	}
+
+	return nil
+}
+
+// browseBackend returns the backend browsing the manifests or the clusters of the kubeconfig
+func (c *CommandOpts) browseBackend(cmd *cobra.Command) (browseBackend, error) {
+	if len(c.filenames) == 0 {
+		return clusterBrowseBackend{backend: c.backend, opts: sourceOptionsFromFlags(cmd)}, nil
+	}
+
+	for _, f := range c.filenames {
+		if f == stdinFilename {
+			return nil, ErrBrowseFromStdin
+		}
+	}
+
+	source, err := newManifestSource(c.filenames, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return manifestBrowseBackend{secrets: source.secrets}, nil
+}
+
+// Init loads the contexts
+func (m browseModel) Init() tea.Cmd {
+	return m.loadContexts()
+}
+
+// loadContexts lists the contexts of the backend
+func (m browseModel) loadContexts() tea.Cmd {
+	seq := m.seq
+	return func() tea.Msg {
+		names, current, err := m.backend.contexts()
+		items := make([]browseItem, 0, len(names))
+		for _, name := range names {
+			items = append(items, browseItem{label: name, value: name})
+		}
+		return browseLoadedMsg{current: current, err: err, items: items, level: browseContexts, seq: seq}
+	}
+}
+
+// loadNamespaces lists the namespaces of the selected context
+//
+// If they can't be listed, e.g. due to missing permissions, the namespace of
+// the context or the one to open is offered instead.
+func (m browseModel) loadNamespaces() tea.Cmd {
+	ctx, seq, kubeContext := m.ctx, m.seq, m.kubeContext
+	fallback := ""
+	if len(m.pending) > 0 {
+		fallback = m.pending[0]
+	}
+
+	return func() tea.Msg {
+		msg := browseLoadedMsg{level: browseNamespaces, seq: seq}
+
+		source, err := m.backend.source(kubeContext, "")
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		namespaces, err := source.ListNamespaces(ctx)
+		if err != nil || len(namespaces) == 0 {
+			label := fallback
+			if label == "" {
+				label = currentNamespaceLabel
+			}
+			msg.items = []browseItem{{detail: "namespaces can't be listed", label: label, value: fallback}}
+			return msg
+		}
+
+		for _, ns := range namespaces {
+			msg.items = append(msg.items, browseItem{label: ns, value: ns})
+		}
+		return msg
+	}
+}
+
+// loadSecrets lists the secrets of the selected namespace including their data
+func (m browseModel) loadSecrets() tea.Cmd {
+	ctx, seq, kubeContext, namespace := m.ctx, m.seq, m.kubeContext, m.namespace
+
+	return func() tea.Msg {
+		msg := browseLoadedMsg{level: browseSecrets, seq: seq}
+
+		source, err := m.backend.source(kubeContext, namespace)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		secretList, err := source.ListSecrets(ctx, ListOptions{})
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		msg.secrets = secretList.Items
+		sort.SliceStable(msg.secrets, func(i, j int) bool {
+			return msg.secrets[i].Metadata.Name < msg.secrets[j].Metadata.Name
+		})
+		for _, s := range msg.secrets {
+			msg.items = append(msg.items, browseItem{detail: string(s.Type), label: s.Metadata.Name, value: s.Metadata.Name})
+		}
+		return msg
+	}
+}
+
+// Update handles loaded levels, timers and key presses
+func (m browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case browseLoadedMsg:
+		return m.loaded(msg)
+	case browseClipboardClearMsg:
+		m.clearClipboard(msg.value)
+		return m, nil
+	case tea.KeyMsg:
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		return m.updateKey(msg)
+	}
+
+	return m, nil
+}
+
+// loaded pushes the list of a level loaded in the background
+//
+// Results of loads which were abandoned by navigating back are dropped.
+func (m browseModel) loaded(msg browseLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.seq {
+		return m, nil
+	}
+	m.loading = false
+
+	if msg.err != nil && msg.level != browseContexts {
+		m.pending = nil
+		m.setStatus(msg.err.Error(), true)
+		return m, nil
+	}
+
+	// without contexts, e.g. for manifests or without a kubeconfig, the backend's current one is browsed
+	if msg.level == browseContexts && len(msg.items) == 0 {
+		if msg.err != nil {
+			m.setStatus(fmt.Sprintf("Contexts unavailable: %v", msg.err), true)
+		}
+		if len(m.pending) > 0 {
+			m.kubeContext, m.pending = m.pending[0], m.pending[1:]
+		}
+		return m.startLoading(m.loadNamespaces)
+	}
+
+	if msg.level == browseSecrets {
+		m.secrets = make(map[string]Secret, len(msg.secrets))
+		for _, s := range msg.secrets {
+			m.secrets[s.Metadata.Name] = s
+		}
+	}
+
+	list := browseList{items: msg.items, level: msg.level}
+	list.applyFilter()
+	if msg.current != "" {
+		list.selectValue(msg.current)
+	}
+	m.lists = append(m.lists, list)
+
+	if len(m.pending) > 0 {
+		value := m.pending[0]
+		m.pending = m.pending[1:]
+		if value == "" || m.current().selectValue(value) {
+			return m.open()
+		}
+		m.setStatus(fmt.Sprintf("%s %q not found", strings.TrimSuffix(browseLevelTitles[msg.level], "s"), value), true)
+		m.pending = nil
+	}
+
+	return m, nil
+}
+
+// startLoading marks the browser as loading and runs the load in the background
+func (m browseModel) startLoading(load func() tea.Cmd) (tea.Model, tea.Cmd) {
+	m.loading = true
+	return m, load()
+}
+
+// updateFilter handles key presses while typing a filter
+func (m browseModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	list := m.current()
+	if list == nil {
+		m.filtering = false
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m.quit()
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		list.filter = ""
+		list.applyFilter()
+	case tea.KeyBackspace:
+		if runes := []rune(list.filter); len(runes) > 0 {
+			list.filter = string(runes[:len(runes)-1])
+			list.applyFilter()
+		}
+	case tea.KeyUp:
+		list.move(-1)
+	case tea.KeyDown:
+		list.move(1)
+	case tea.KeyRunes, tea.KeySpace:
+		list.filter += string(msg.Runes)
+		list.applyFilter()
+	}
+	m.diffText = ""
+
+	return m, nil
+}
+
+// updateKey handles key presses while navigating
+func (m browseModel) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m.quit()
+	}
+
+	if m.loading {
+		if key := msg.String(); key == "esc" || key == "left" || key == "h" || key == "backspace" {
+			return m.back()
+		}
+		return m, nil
+	}
+
+	list := m.current()
+	if list == nil {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		list.move(-1)
+		m.diffText = ""
+	case "down", "j":
+		list.move(1)
+		m.diffText = ""
+	case "enter", "right", "l":
+		return m.open()
+	case "esc":
+		if list.filter != "" {
+			list.filter = ""
+			list.applyFilter()
+			return m, nil
+		}
+		return m.back()
+	case "left", "h", "backspace":
+		return m.back()
+	case "/":
+		m.filtering = true
+	case "r":
+		m.revealed = !m.revealed
+	case "c":
+		return m.copy()
+	case "e":
+		m.export()
+	case "d":
+		m.diff()
+	}
+
+	return m, nil
+}
+
+// open descends into the selected entry
+func (m browseModel) open() (tea.Model, tea.Cmd) {
+	list := m.current()
+	item, ok := list.selected()
+	if !ok {
+		return m, nil
+	}
+	m.diffText = ""
+	m.seq++
+
+	switch list.level {
+	case browseContexts:
+		m.kubeContext = item.value
+		return m.startLoading(m.loadNamespaces)
+	case browseNamespaces:
+		m.namespace = item.value
+		return m.startLoading(m.loadSecrets)
+	case browseSecrets:
+		secret := m.secrets[item.value]
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		keyList := browseList{level: browseKeys}
+		for _, k := range keys {
+			keyList.items = append(keyList.items, browseItem{label: k, value: k})
+		}
+		keyList.applyFilter()
+		m.lists = append(m.lists, keyList)
+	}
+
+	return m, nil
+}
+
+// back returns to the previous level or abandons a running load
+func (m browseModel) back() (tea.Model, tea.Cmd) {
+	m.diffText = ""
+	m.pending = nil
+	m.seq++
+
+	if m.loading {
+		m.loading = false
+		return m, nil
+	}
+
+	if len(m.lists) > 1 {
+		m.lists = m.lists[:len(m.lists)-1]
+	}
+
+	return m, nil
+}
+
+// quit clears a copied value from the clipboard and exits
+func (m browseModel) quit() (tea.Model, tea.Cmd) {
+	if m.copied != "" {
+		m.clearClipboard(m.copied)
+	}
+	return m, tea.Quit
+}
+
+// copy copies the selected value to the clipboard and schedules clearing it
+func (m browseModel) copy() (tea.Model, tea.Cmd) {
+	secret, key, ok := m.selectedKey()
+	if !ok {
+		m.setStatus("Select a key to copy", true)
+		return m, nil
+	}
+	if m.clipboardErr != nil {
+		m.setStatus(m.clipboardErr.Error(), true)
+		return m, nil
+	}
+
+	kv, err := decodeKeyValue(secret, key, secret.Data[key], ProcessOptions{})
+	if err != nil {
+		m.setStatus(err.Error(), true)
+		return m, nil
+	}
+	if kv.Encoding == encodingBase64 {
+		m.setStatus(fmt.Sprintf("%v: %s holds binary data, export it instead", ErrClipboardUnsupportedValue, key), true)
+		return m, nil
+	}
+
+	if err := m.clipboard.Write(kv.Value); err != nil {
+		m.setStatus(fmt.Sprintf("failed to copy to clipboard: %v", err), true)
+		return m, nil
+	}
+
+	if m.clipboardClear <= 0 {
+		m.setStatus(fmt.Sprintf("Copied %s to the clipboard", key), false)
+		return m, nil
+	}
+
+	m.copied = kv.Value
+	m.setStatus(fmt.Sprintf("Copied %s to the clipboard, clearing in %s", key, m.clipboardClear), false)
+	value := kv.Value
+
+	return m, tea.Tick(m.clipboardClear, func(time.Time) tea.Msg {
+		return browseClipboardClearMsg{value: value}
+	})
+}
+
+// clearClipboard clears the clipboard if it still holds the copied value
+func (m *browseModel) clearClipboard(value string) {
+	if m.clipboard == nil || value != m.copied {
+		return
+	}
+	m.copied = ""
+
+	content, ok, err := m.clipboard.Read()
+	if err != nil || (ok && content != value) {
+		m.setStatus("Clipboard content changed, not clearing", false)
+		return
+	}
+
+	if err := m.clipboard.Write(""); err != nil {
+		m.setStatus(fmt.Sprintf("failed to clear clipboard: %v", err), true)
+		return
+	}
+	m.setStatus("Cleared the clipboard", false)
+}
+
+// export writes the keys of the selected secret to a directory named after it like a secret volume mount
+//
+// The directory is created below --to-dir or the working directory, the
+// status shows its absolute path.
+func (m *browseModel) export() {
+	secret, ok := m.selectedSecret()
+	if !ok {
+		m.setStatus("Select a secret to export", true)
+		return
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	dir := filepath.Join(m.exportDir, secret.Metadata.Name)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if err := writeSecretToDir(io.Discard, secret, keys, ProcessOptions{Force: m.exportForce, ToDir: dir}); err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+	m.setStatus(fmt.Sprintf("Exported %d keys to %s", len(keys), dir), false)
+}
+
+// diff marks the selected secret or compares the marked secret with it
+//
+// The marked secret is kept while navigating, so secrets can be compared
+// across namespaces and contexts.
+func (m *browseModel) diff() {
+	secret, ok := m.selectedSecret()
+	if !ok {
+		m.setStatus("Select a secret to diff", true)
+		return
+	}
+	ref := SecretRef{Context: m.kubeContext, Name: secret.Metadata.Name, Namespace: secret.Metadata.Namespace}
+
+	if m.diffFrom == nil {
+		m.diffFrom, m.diffRef = &secret, ref
+		m.setStatus(fmt.Sprintf("Marked %s, press d on another secret to compare", ref), false)
+		return
+	}
+
+	from, fromRef := *m.diffFrom, m.diffRef
+	m.diffFrom = nil
+	if fromRef == ref {
+		m.setStatus(fmt.Sprintf("Unmarked %s", ref), false)
+		return
+	}
+
+	report, err := diffSecrets(from, secret, fromRef, ref, secretDiffOptions{reveal: m.revealed, values: true})
+	if err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.renderText(&buf); err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+	m.diffText = buf.String()
+	m.setStatus(fmt.Sprintf("Compared %s with %s", fromRef, ref), false)
+}
+
+// setStatus shows a message in the status line
+func (m *browseModel) setStatus(status string, isErr bool) {
+	m.status, m.statusErr = status, isErr
+}
+
+// current returns the list of the current level
+func (m browseModel) current() *browseList {
+	if len(m.lists) == 0 {
+		return nil
+	}
+	return &m.lists[len(m.lists)-1]
+}
+
+// selectedSecret returns the secret selected in the secret list or whose keys are shown
+func (m browseModel) selectedSecret() (Secret, bool) {
+	for i := len(m.lists) - 1; i >= 0; i-- {
+		if m.lists[i].level != browseSecrets {
+			continue
+		}
+		item, ok := m.lists[i].selected()
+		if !ok {
+			return Secret{}, false
+		}
+		secret, ok := m.secrets[item.value]
+		return secret, ok
+	}
+	return Secret{}, false
+}
+
+// selectedKey returns the selected key, or the only key of the selected secret
+func (m browseModel) selectedKey() (Secret, string, bool) {
+	secret, ok := m.selectedSecret()
+	if !ok {
+		return Secret{}, "", false
+	}
+
+	list := m.current()
+	if list.level == browseKeys {
+		item, ok := list.selected()
+		return secret, item.value, ok
+	}
+
+	if len(secret.Data) == 1 {
+		for k := range secret.Data {
+			return secret, k, true
+		}
+	}
+
+	return Secret{}, "", false
+}
+
+// masking returns the masking applied to the preview
+func (m browseModel) masking() Masking {
+	if m.revealed {
+		return Masking{}
+	}
+	return m.mask
+}
+
+// View renders the breadcrumb, the list, the preview and the status line
+func (m browseModel) View() string {
+	listWidth := max(m.width/3, 24)
+	previewWidth := max(m.width-listWidth-4, 20)
+	bodyHeight := max(m.height-6, 3)
+
+	left := browsePaneStyle.Width(listWidth - 2).Height(bodyHeight).Render(m.renderList(listWidth-4, bodyHeight))
+	right := browsePaneStyle.Width(previewWidth - 2).Height(bodyHeight).Render(
+		lipgloss.NewStyle().MaxWidth(previewWidth - 4).MaxHeight(bodyHeight).Render(m.preview()),
+	)
+
+	footer := browseDimStyle.Render(browseHelp)
+	status := m.status
+	if m.filtering {
+		status = fmt.Sprintf(browseFilterOn, m.current().filter)
+	}
+	if m.statusErr && !m.filtering {
+		status = browseErrorStyle.Render(status)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		browseTitleStyle.Render(m.breadcrumb()),
+		lipgloss.JoinHorizontal(lipgloss.Top, left, right),
+		status,
+		footer,
+	)
+}
+
+// breadcrumb returns the path to the current level
+func (m browseModel) breadcrumb() string {
+	parts := []string{"view-secret"}
+	for _, l := range m.lists[:max(len(m.lists)-1, 0)] {
+		if item, ok := l.selected(); ok {
+			parts = append(parts, item.label)
+		}
+	}
+	if m.revealed {
+		parts = append(parts, "[revealed]")
+	}
+	return strings.Join(parts, " › ")
+}
+
+// renderList renders the entries of the current level matching the filter
+//
+// The entries are scrolled so the selected one stays visible.
+func (m browseModel) renderList(width, height int) string {
+	list := m.current()
+	if list == nil {
+		if m.loading {
+			return browseDimStyle.Render("Loading…")
+		}
+		return ""
+	}
+
+	title := browseLevelTitles[list.level]
+	if list.filter != "" {
+		title += fmt.Sprintf(" (%d/%d)", len(list.matches), len(list.items))
+	}
+	lines := []string{browseTitleStyle.Render(title)}
+	if m.loading {
+		lines = append(lines, browseDimStyle.Render("Loading…"))
+	}
+
+	visible := max(height-len(lines), 1)
+	start := max(list.cursor-visible+1, 0)
+	for i := start; i < len(list.matches) && i < start+visible; i++ {
+		item := list.items[list.matches[i]]
+		line := item.label
+		if item.detail != "" {
+			line += "  " + browseDimStyle.Render(item.detail)
+		}
+		line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+		if i == list.cursor {
+			line = browseSelectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// preview renders the type-aware view of the selected entry
+//
+// Secrets show their structured view if there's one for their type and the
+// decoded values otherwise, keys show their decoded value. Values and
+// credentials are masked unless revealed.
+func (m browseModel) preview() string {
+	if m.diffText != "" {
+		return m.diffText
+	}
+
+	list := m.current()
+	if list == nil {
+		return ""
+	}
+	item, ok := list.selected()
+	if !ok {
+		return browseDimStyle.Render("No matches")
+	}
+
+	switch list.level {
+	case browseContexts:
+		return "Context " + item.label
+	case browseNamespaces:
+		return "Namespace " + item.label
+	case browseSecrets:
+		return m.secretPreview(m.secrets[item.value])
+	default:
+		secret, _ := m.selectedSecret()
+		return m.keyPreview(secret, item.value)
+	}
+}
+
+// secretPreview renders the structured view or all decoded values of a secret
+func (m browseModel) secretPreview(secret Secret) string {
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "Type: %s\nKeys: %d\n\n", secret.Type, len(secret.Data))
+
+	mask := m.masking()
+	details, err := secretDetails(secret, DetailsOptions{Mask: mask, Now: m.now()})
+	if err == nil {
+		var detailsBuf bytes.Buffer
+		if err = details.renderText(&detailsBuf); err == nil {
+			buf.Write(detailsBuf.Bytes())
+			return buf.String()
+		}
+	}
+
+	data, err := decodeAllData(secret, secret.Data, ProcessOptions{})
+	if err != nil {
+		return buf.String() + browseErrorStyle.Render(err.Error())
+	}
+	if err := writeText(&buf, maskKeyValues(data, mask, nil), true); err != nil {
+		return buf.String() + browseErrorStyle.Render(err.Error())
+	}
+
+	return buf.String()
+}
+
+// keyPreview renders the decoded value of a single key, binary values as a hexdump
+func (m browseModel) keyPreview(secret Secret, key string) string {
+	kv, err := decodeKeyValue(secret, key, secret.Data[key], ProcessOptions{})
+	if err != nil {
+		return browseErrorStyle.Render(err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := writeText(&buf, maskKeyValues([]KeyValue{kv}, m.masking(), nil), false); err != nil {
+		return browseErrorStyle.Render(err.Error())
+	}
+
+	return buf.String()
+}
+
+// applyFilter updates the entries matching the filter ordered by their fuzzy score
+func (l *browseList) applyFilter() {
+	type scored struct {
+		index int
+		score int
+	}
+
+	var matches []scored
+	for i, item := range l.items {
+		if score, ok := fuzzyScore(l.filter, item.label); ok {
+			matches = append(matches, scored{index: i, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	l.matches = make([]int, 0, len(matches))
+	for _, s := range matches {
+		l.matches = append(l.matches, s.index)
+	}
+	l.cursor = 0
+}
+
+// move moves the cursor by delta entries within the matches
+func (l *browseList) move(delta int) {
+	l.cursor = min(max(l.cursor+delta, 0), max(len(l.matches)-1, 0))
+}
+
+// selected returns the entry under the cursor
+func (l browseList) selected() (browseItem, bool) {
+	if l.cursor >= len(l.matches) {
+		return browseItem{}, false
+	}
+	return l.items[l.matches[l.cursor]], true
+}
+
+// selectValue moves the cursor to the entry with the value and reports whether it's listed
+func (l *browseList) selectValue(value string) bool {
+	for i, index := range l.matches {
+		if l.items[index].value == value {
+			l.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyScore reports whether the characters of the pattern appear in order in the text, ignoring case
+//
+// Consecutive characters and characters at the start of a word score higher
+// so that closer matches are listed first.
+func fuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+
+	score, pi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2
+		}
+		if ti == 0 || strings.ContainsRune("-_./: ", t[ti-1]) {
+			score += 3
+		}
+		prev = ti
+		pi++
+	}
+
+	return score, pi == len(p)
+}
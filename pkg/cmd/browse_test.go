@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBrowseBackend serves the same secrets for every context and records the sources opened
+type fakeBrowseBackend struct {
+	current string
+	names   []string
+	opened  *[]string
+	secrets *fakeSource
+}
+
+func (b fakeBrowseBackend) contexts() ([]string, string, error) {
+	return b.names, b.current, nil
+}
+
+func (b fakeBrowseBackend) source(kubeContext, namespace string) (SecretSource, error) {
+	*b.opened = append(*b.opened, kubeContext+"/"+namespace)
+	source := *b.secrets
+	source.namespace = namespace
+	return &source, nil
+}
+
+// newBrowseTestModel creates a browser over a database and an API secret in two namespaces
+func newBrowseTestModel(t *testing.T, kubeContext, namespace string) (browseModel, *[]string) {
+	t.Helper()
+
+	opened := &[]string{}
+	backend := fakeBrowseBackend{
+		current: "prod",
+		names:   []string{"dev", "prod"},
+		opened:  opened,
+		secrets: &fakeSource{
+			namespaces: []string{"apps", "default"},
+			secrets: []Secret{
+				newTestSecret(Opaque, "apps", "db", map[string]string{"password": "s3cret-db-pass", "user": "app"}),
+				newTestSecret(Opaque, "apps", "api", map[string]string{"token": "api-token-value", "user": "app"}),
+				newTestSecret(Opaque, "default", "db", map[string]string{"password": "other-pass", "user": "app"}),
+			},
+		},
+	}
+
+	m := newBrowseModel(backend, Masking{}, kubeContext, namespace)
+	return drainBrowse(t, m, m.Init()), opened
+}
+
+// drainBrowse runs the background loads synchronously until the browser is idle
+func drainBrowse(t *testing.T, m browseModel, cmd tea.Cmd) browseModel {
+	t.Helper()
+
+	for cmd != nil {
+		msg, ok := cmd().(browseLoadedMsg)
+		if !ok {
+			break
+		}
+		var model tea.Model
+		model, cmd = m.Update(msg)
+		m = model.(browseModel)
+	}
+
+	return m
+}
+
+// pressBrowse sends the keys to the browser one by one
+func pressBrowse(t *testing.T, m browseModel, keys ...string) browseModel {
+	t.Helper()
+
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+
+		model, cmd := m.Update(msg)
+		m = drainBrowse(t, model.(browseModel), cmd)
+	}
+
+	return m
+}
+
+func TestBrowseNavigation(t *testing.T) {
+	m, opened := newBrowseTestModel(t, "", "")
+
+	// the current context is preselected
+	item, _ := m.current().selected()
+	assert.Equal(t, "prod", item.value)
+
+	m = pressBrowse(t, m, "enter", "enter")
+	assert.Equal(t, browseSecrets, m.current().level)
+	assert.Equal(t, []string{"prod/", "prod/apps"}, *opened)
+	assert.Contains(t, m.View(), "view-secret › prod › apps")
+
+	// secrets are sorted and previewed with their values masked
+	item, _ = m.current().selected()
+	assert.Equal(t, "api", item.value)
+	assert.Contains(t, m.preview(), "token='********'")
+
+	m = pressBrowse(t, m, "down", "enter")
+	assert.Equal(t, browseKeys, m.current().level)
+	assert.Equal(t, "********\n", m.preview())
+
+	m = pressBrowse(t, m, "r")
+	assert.Equal(t, "s3cret-db-pass\n", m.preview())
+	assert.Contains(t, m.View(), "[revealed]")
+
+	// going back restores the cursor of the previous level
+	m = pressBrowse(t, m, "esc")
+	item, _ = m.current().selected()
+	assert.Equal(t, "db", item.value)
+
+	m = pressBrowse(t, m, "esc", "esc", "esc")
+	assert.Equal(t, browseContexts, m.current().level)
+	assert.Len(t, m.lists, 1)
+}
+
+func TestBrowseStartPath(t *testing.T) {
+	m, opened := newBrowseTestModel(t, "dev", "default")
+
+	assert.Equal(t, browseSecrets, m.current().level)
+	assert.Len(t, m.lists, 3)
+	assert.Equal(t, []string{"dev/", "dev/default"}, *opened)
+	assert.Contains(t, m.preview(), "password='********'")
+
+	m, _ = newBrowseTestModel(t, "staging", "")
+	assert.Equal(t, browseContexts, m.current().level)
+	assert.True(t, m.statusErr)
+	assert.Contains(t, m.status, `Context "staging" not found`)
+}
+
+func TestBrowseManifests(t *testing.T) {
+	secrets := []Secret{newTestSecret(Opaque, "apps", "db", map[string]string{"password": "pass"})}
+	m := newBrowseModel(manifestBrowseBackend{secrets: secrets}, Masking{Mode: MaskLength}, "", "")
+	m = drainBrowse(t, m, m.Init())
+
+	// without contexts the browser starts at the namespaces
+	assert.Equal(t, browseNamespaces, m.current().level)
+
+	m = pressBrowse(t, m, "enter", "enter")
+	assert.Equal(t, "[4 characters]\n", m.preview())
+}
+
+func TestBrowseFilter(t *testing.T) {
+	m, _ := newBrowseTestModel(t, "prod", "apps")
+	m = pressBrowse(t, m, "down", "enter")
+
+	m = pressBrowse(t, m, "/", "p", "w")
+	assert.True(t, m.filtering)
+	assert.Equal(t, []int{0}, m.current().matches)
+	assert.Contains(t, m.View(), "filter: pw")
+
+	m = pressBrowse(t, m, "backspace", "backspace", "u", "enter")
+	assert.False(t, m.filtering)
+	assert.Equal(t, "u", m.current().filter)
+	item, _ := m.current().selected()
+	assert.Equal(t, "user", item.value)
+
+	// esc clears the filter before navigating back
+	m = pressBrowse(t, m, "esc")
+	assert.Equal(t, browseKeys, m.current().level)
+	assert.Len(t, m.current().matches, 2)
+}
+
+func TestBrowseActions(t *testing.T) {
+	m, _ := newBrowseTestModel(t, "prod", "apps")
+
+	// diff api with db
+	m = pressBrowse(t, m, "d", "down", "d")
+	assert.Contains(t, m.preview(), "password  added")
+	assert.Contains(t, m.preview(), "token     removed")
+	assert.Contains(t, m.preview(), "user      unchanged")
+	assert.Contains(t, m.status, "Compared prod:apps/api with prod:apps/db")
+
+	m.exportDir = t.TempDir()
+	m = pressBrowse(t, m, "e")
+	content, err := os.ReadFile(filepath.Join(m.exportDir, "db", "password"))
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret-db-pass", string(content))
+	assert.Equal(t, "Exported 2 keys to "+filepath.Join(m.exportDir, "db"), m.status)
+
+	// exporting again needs --force to replace the files
+	m = pressBrowse(t, m, "e")
+	assert.True(t, m.statusErr)
+	m.exportForce = true
+	m = pressBrowse(t, m, "e")
+	assert.False(t, m.statusErr)
+
+	// copying requires a key unless the secret only has one
+	cb := &fakeClipboard{readable: true}
+	m.clipboard, m.clipboardClear = cb, time.Millisecond
+	m = pressBrowse(t, m, "c")
+	assert.True(t, m.statusErr)
+	assert.Empty(t, cb.writes)
+
+	m = pressBrowse(t, m, "enter", "c")
+	assert.Equal(t, []string{"s3cret-db-pass"}, cb.writes)
+
+	model, _ := m.Update(browseClipboardClearMsg{value: "s3cret-db-pass"})
+	m = model.(browseModel)
+	assert.Equal(t, []string{"s3cret-db-pass", ""}, cb.writes)
+	assert.Equal(t, "Cleared the clipboard", m.status)
+
+	// quitting clears a value which is still in the clipboard
+	m = pressBrowse(t, m, "c")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	assert.Equal(t, tea.Quit(), cmd())
+	assert.Equal(t, []string{"s3cret-db-pass", "", "s3cret-db-pass", ""}, cb.writes)
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		text    string
+		wantOk  bool
+	}{
+		"empty pattern": {pattern: "", text: "anything", wantOk: true},
+		"subsequence":   {pattern: "dbp", text: "db-password", wantOk: true},
+		"ignores case":  {pattern: "TLS", text: "ingress-tls", wantOk: true},
+		"out of order":  {pattern: "wb", text: "db-password", wantOk: false},
+		"missing":       {pattern: "x", text: "db-password", wantOk: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, ok := fuzzyScore(tt.pattern, tt.text)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+
+	// consecutive characters at the start of a word rank first
+	prefix, _ := fuzzyScore("pass", "db-password")
+	scattered, _ := fuzzyScore("pass", "pxaxsxs")
+	assert.Greater(t, prefix, scattered)
+}
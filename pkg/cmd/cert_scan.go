@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	certScanExample = `
+	# report certificates expiring within 30 days in the current namespace
+	%[1]s view-secret cert-scan
+
+	# scan all namespaces with custom thresholds in days
+	%[1]s view-secret cert-scan -A --warn 60 --critical 14
+
+	# emit a json report for alerting pipelines
+	%[1]s view-secret cert-scan -A -o json
+
+Exit codes:
+	0  all certificates are valid beyond the warning window
+	1  the scan failed
+	2  at least one certificate expires within the warning window
+	3  at least one certificate expires within the critical window
+	4  at least one certificate has expired
+`
+
+	certScanSummary = "Scanned %d certificates in %d secrets: %d ok, %d warning, %d critical, %d expired\n"
+
+	// pemCertificateHeader marks the beginning of a PEM encoded certificate
+	pemCertificateHeader = "-----BEGIN CERTIFICATE-----"
+)
+
+// CertStatus represents the expiry status of a certificate
+type CertStatus string
+
+const (
+	CertStatusOK       CertStatus = "ok"
+	CertStatusWarning  CertStatus = "warning"
+	CertStatusCritical CertStatus = "critical"
+	CertStatusExpired  CertStatus = "expired"
+)
+
+// certStatusSeverity orders the statuses by severity, it doubles as the exit code
+var certStatusSeverity = map[CertStatus]int{
+	CertStatusOK:       0,
+	CertStatusWarning:  2,
+	CertStatusCritical: 3,
+	CertStatusExpired:  4,
+}
+
+// ErrInvalidThresholds is thrown if the critical window is larger than the warning window
+var ErrInvalidThresholds = errors.New("--critical must not be larger than --warn")
+
+// CertScanOpts is the struct holding the properties of the cert-scan subcommand
+type CertScanOpts struct {
+	sourceFlags
+
+	allNamespaces bool
+	criticalDays  int
+	outputFormat  string
+	source        SecretSource
+	warnDays      int
+}
+
+// CertificateFinding represents a certificate found during a scan
+type CertificateFinding struct {
+	DaysUntilExpiry int        `json:"daysUntilExpiry" yaml:"daysUntilExpiry"`
+	Key             string     `json:"key" yaml:"key"`
+	Name            string     `json:"name" yaml:"name"`
+	Namespace       string     `json:"namespace" yaml:"namespace"`
+	NotAfter        time.Time  `json:"notAfter" yaml:"notAfter"`
+	Status          CertStatus `json:"status" yaml:"status"`
+	Subject         string     `json:"subject" yaml:"subject"`
+}
+
+// CertScanSummary holds the number of certificates per status
+type CertScanSummary struct {
+	Critical int        `json:"critical" yaml:"critical"`
+	Expired  int        `json:"expired" yaml:"expired"`
+	OK       int        `json:"ok" yaml:"ok"`
+	Secrets  int        `json:"secrets" yaml:"secrets"`
+	Status   CertStatus `json:"status" yaml:"status"`
+	Warning  int        `json:"warning" yaml:"warning"`
+}
+
+// CertScanReport is the result of a certificate expiry scan
+//
+// Only certificates which aren't ok are listed, the summary covers all of them.
+type CertScanReport struct {
+	Certificates []CertificateFinding `json:"certificates" yaml:"certificates"`
+	Summary      CertScanSummary      `json:"summary" yaml:"summary"`
+}
+
+// newCmdCertScan creates the cobra command scanning secrets for expiring certificates
+func newCmdCertScan() *cobra.Command {
+	res := &CertScanOpts{}
+
+	cmd := &cobra.Command{
+		Args:         cobra.NoArgs,
+		Example:      fmt.Sprintf(certScanExample, "kubectl"),
+		Short:        "Report certificates in secrets that expire within a warning or critical window",
+		SilenceUsage: true,
+		Use:          "cert-scan",
+		RunE: func(c *cobra.Command, _ []string) error {
+			return res.Scan(c)
+		},
+	}
+
+	res.sourceFlags.addFlags(cmd)
+	cmd.Flags().BoolVarP(&res.allNamespaces, "all-namespaces", "A", res.allNamespaces, "if true, scans secrets across all namespaces")
+	cmd.Flags().IntVar(&res.warnDays, "warn", 30, "report certificates expiring within this many days as warning")
+	cmd.Flags().IntVar(&res.criticalDays, "critical", 7, "report certificates expiring within this many days as critical")
+	cmd.Flags().StringVarP(&res.outputFormat, "output", "o", "text", "output format: text, json, yaml")
+
+	return cmd
+}
+
+// Scan lists the secrets and reports the certificates expiring within the configured windows
+func (o *CertScanOpts) Scan(cmd *cobra.Command) error {
+	if o.criticalDays > o.warnDays {
+		return ErrInvalidThresholds
+	}
+
+	source := o.source
+	if source == nil {
+		var err error
+		if source, err = newSecretSourceFromFlags(cmd); err != nil {
+			return err
+		}
+	}
+
+	secretList, err := source.ListSecrets(contextFromCommand(cmd), ListOptions{AllNamespaces: o.allNamespaces})
+	if err != nil {
+		return err
+	}
+
+	report := scanCertificates(secretList.Items, time.Now(), o.warnDays, o.criticalDays, cmd.ErrOrStderr())
+
+	return ProcessCertScan(cmd.OutOrStdout(), cmd.ErrOrStderr(), report, o.outputFormat)
+}
+
+// ProcessCertScan outputs the scan report and returns an ExitError unless all certificates are ok
+func ProcessCertScan(outWriter, errWriter io.Writer, report CertScanReport, outputFormat string) error {
+	var err error
+	switch outputFormat {
+	case "json":
+		err = writeJSON(outWriter, report)
+	case "yaml":
+		err = writeYAML(outWriter, report)
+	default:
+		err = report.renderText(outWriter)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	s := report.Summary
+	total := s.OK + s.Warning + s.Critical + s.Expired
+	if _, err := fmt.Fprintf(errWriter, certScanSummary, total, s.Secrets, s.OK, s.Warning, s.Critical, s.Expired); err != nil {
+		return fmt.Errorf("failed to write to stderr: %w", err)
+	}
+
+	if s.Status == CertStatusOK {
+		return nil
+	}
+
+	return &ExitError{
+		Code:    certStatusSeverity[s.Status],
+		Message: fmt.Sprintf("found certificates with status %s", s.Status),
+	}
+}
+
+// renderText outputs the findings as a table
+func (r CertScanReport) renderText(outWriter io.Writer) error {
+	if len(r.Certificates) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tSECRET\tKEY\tSUBJECT\tNOT AFTER\tDAYS\tSTATUS")
+	for _, c := range r.Certificates {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", c.Namespace, c.Name, c.Key, c.Subject, c.NotAfter.Format(time.RFC3339), c.DaysUntilExpiry, c.Status)
+	}
+
+	return w.Flush()
+}
+
+// scanCertificates checks all PEM certificates in TLS and Opaque secrets against the thresholds
+//
+// Values that look like certificates but can't be parsed are reported on the
+// error writer and otherwise skipped so that a single bad secret doesn't
+// prevent the rest of the scan.
+func scanCertificates(secrets []Secret, now time.Time, warnDays, criticalDays int, errWriter io.Writer) CertScanReport {
+	report := CertScanReport{
+		Certificates: []CertificateFinding{},
+		Summary:      CertScanSummary{Status: CertStatusOK},
+	}
+
+	for _, secret := range secrets {
+		if secret.Type != TLS && secret.Type != Opaque {
+			continue
+		}
+
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		found := false
+		for _, key := range keys {
+			decoded, err := base64.StdEncoding.DecodeString(secret.Data[key])
+			if err != nil || !strings.Contains(string(decoded), pemCertificateHeader) {
+				continue
+			}
+
+			certs, err := parseCertificates(decoded)
+			if err != nil {
+				_, _ = fmt.Fprintf(errWriter, "Skipping %s/%s %s: %v\n", secret.Metadata.Namespace, secret.Metadata.Name, key, err)
+				continue
+			}
+			found = true
+
+			for i, cert := range certs {
+				info := newCertificateInfo(cert, certSource(key, i, len(certs)), now)
+				status := certStatus(info, warnDays, criticalDays)
+				report.Summary.add(status)
+
+				if status == CertStatusOK {
+					continue
+				}
+				report.Certificates = append(report.Certificates, CertificateFinding{
+					DaysUntilExpiry: info.DaysUntilExpiry,
+					Key:             info.Source,
+					Name:            secret.Metadata.Name,
+					Namespace:       secret.Metadata.Namespace,
+					NotAfter:        info.NotAfter,
+					Status:          status,
+					Subject:         info.Subject,
+				})
+			}
+		}
+
+		if found {
+			report.Summary.Secrets++
+		}
+	}
+
+	sort.SliceStable(report.Certificates, func(i, j int) bool {
+		return report.Certificates[i].NotAfter.Before(report.Certificates[j].NotAfter)
+	})
+
+	return report
+}
+
+// certStatus classifies a certificate by the days left until it expires
+func certStatus(info CertificateInfo, warnDays, criticalDays int) CertStatus {
+	switch {
+	case info.Expired:
+		return CertStatusExpired
+	case info.DaysUntilExpiry < criticalDays:
+		return CertStatusCritical
+	case info.DaysUntilExpiry < warnDays:
+		return CertStatusWarning
+	default:
+		return CertStatusOK
+	}
+}
+
+// add counts a certificate and raises the overall status if needed
+func (s *CertScanSummary) add(status CertStatus) {
+	switch status {
+	case CertStatusOK:
+		s.OK++
+	case CertStatusWarning:
+		s.Warning++
+	case CertStatusCritical:
+		s.Critical++
+	case CertStatusExpired:
+		s.Expired++
+	}
+
+	if certStatusSeverity[status] > certStatusSeverity[s.Status] {
+		s.Status = status
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanCertificates(t *testing.T) {
+	ca := newTestCert(t, "Test CA", 1, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	warning := newTestCert(t, "warning.example.com", 2, time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC), &ca)
+	critical := newTestCert(t, "critical.example.com", 3, time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC), &ca)
+	expired := newTestCert(t, "expired.example.com", 4, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), &ca)
+
+	opaqueBundle := Secret{
+		Data: SecretData{
+			"bundle.pem": base64.StdEncoding.EncodeToString(append(critical.certPEM, ca.certPEM...)),
+			"password":   "c2VjcmV0Cg==",
+		},
+		Metadata: Metadata{Name: "bundle", Namespace: "apps"},
+		Type:     Opaque,
+	}
+	warnTLS := newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: string(warning.certPEM), tlsKeyKey: string(warning.keyPEM)})
+	expiredTLS := newTestSecret(TLS, "default", "old", map[string]string{tlsCertKey: string(expired.certPEM)})
+	caTLS := newTestSecret(TLS, "default", "ca", map[string]string{tlsCertKey: string(ca.certPEM)})
+	broken := Secret{
+		Data:     SecretData{"tls.crt": base64.StdEncoding.EncodeToString([]byte(pemCertificateHeader + "\ngarbage\n-----END CERTIFICATE-----\n"))},
+		Metadata: Metadata{Name: "broken", Namespace: "default"},
+		Type:     TLS,
+	}
+	// certificates in other secret types are out of scope
+	basicAuth := Secret{Data: SecretData{"password": base64.StdEncoding.EncodeToString(expired.certPEM)}, Type: BasicAuth}
+
+	tests := map[string]struct {
+		secrets      []Secret
+		wantStatus   CertStatus
+		wantFindings []string
+		wantSummary  CertScanSummary
+	}{
+		"all ok": {
+			secrets:     []Secret{caTLS, basicAuth},
+			wantStatus:  CertStatusOK,
+			wantSummary: CertScanSummary{OK: 1, Secrets: 1, Status: CertStatusOK},
+		},
+		"warning": {
+			secrets:      []Secret{caTLS, warnTLS},
+			wantStatus:   CertStatusWarning,
+			wantFindings: []string{"CN=warning.example.com"},
+			wantSummary:  CertScanSummary{OK: 1, Warning: 1, Secrets: 2, Status: CertStatusWarning},
+		},
+		"critical in opaque bundle": {
+			secrets:      []Secret{warnTLS, opaqueBundle, broken},
+			wantStatus:   CertStatusCritical,
+			wantFindings: []string{"CN=critical.example.com", "CN=warning.example.com"},
+			wantSummary:  CertScanSummary{OK: 1, Warning: 1, Critical: 1, Secrets: 2, Status: CertStatusCritical},
+		},
+		"expired": {
+			secrets:      []Secret{warnTLS, opaqueBundle, expiredTLS},
+			wantStatus:   CertStatusExpired,
+			wantFindings: []string{"CN=expired.example.com", "CN=critical.example.com", "CN=warning.example.com"},
+			wantSummary:  CertScanSummary{OK: 1, Warning: 1, Critical: 1, Expired: 1, Secrets: 3, Status: CertStatusExpired},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var errBuf bytes.Buffer
+			report := scanCertificates(tt.secrets, tlsTestNow, 30, 7, &errBuf)
+
+			var subjects []string
+			for _, c := range report.Certificates {
+				subjects = append(subjects, c.Subject)
+			}
+			assert.Equal(t, tt.wantFindings, subjects)
+			assert.Equal(t, tt.wantSummary, report.Summary)
+
+			var outBuf bytes.Buffer
+			err := ProcessCertScan(&outBuf, &errBuf, report, "text")
+			if tt.wantStatus == CertStatusOK {
+				assert.NoError(t, err)
+				return
+			}
+			var exitErr *ExitError
+			if assert.ErrorAs(t, err, &exitErr) {
+				assert.Equal(t, certStatusSeverity[tt.wantStatus], exitErr.Code)
+			}
+		})
+	}
+}
+
+func TestCertScanCommand(t *testing.T) {
+	ca := newTestCert(t, "Test CA", 1, time.Now().AddDate(5, 0, 0), nil)
+	soon := newTestCert(t, "soon.example.com", 2, time.Now().Add(36*time.Hour), &ca)
+
+	okSecret := newTestSecret(TLS, "default", "ca", map[string]string{tlsCertKey: string(ca.certPEM)})
+	soonSecret := newTestSecret(TLS, "apps", "soon", map[string]string{tlsCertKey: string(soon.certPEM)})
+	source := &fakeSource{namespace: "default", secrets: []Secret{okSecret, soonSecret}}
+
+	tests := map[string]struct {
+		opts     CertScanOpts
+		wantCode int
+		wantOut  string
+		wantErr  error
+	}{
+		"namespace":       {opts: CertScanOpts{warnDays: 30, criticalDays: 7, outputFormat: "text"}},
+		"all namespaces":  {opts: CertScanOpts{allNamespaces: true, warnDays: 30, criticalDays: 7, outputFormat: "json"}, wantCode: 3, wantOut: `"status": "critical"`},
+		"custom windows":  {opts: CertScanOpts{allNamespaces: true, warnDays: 2, criticalDays: 1, outputFormat: "text"}, wantCode: 2, wantOut: "apps       soon    tls.crt"},
+		"invalid windows": {opts: CertScanOpts{warnDays: 1, criticalDays: 7}, wantErr: ErrInvalidThresholds},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+
+			opts := tt.opts
+			opts.source = source
+			err := opts.Scan(cmd)
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantCode == 0:
+				assert.NoError(t, err)
+			default:
+				var exitErr *ExitError
+				if assert.ErrorAs(t, err, &exitErr) {
+					assert.Equal(t, tt.wantCode, exitErr.Code)
+				}
+			}
+			assert.Contains(t, outBuf.String(), tt.wantOut)
+		})
+	}
+}
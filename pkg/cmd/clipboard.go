@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"golang.org/x/term"
+)
+
+// defaultClipboardClear is the time after which a copied value is cleared from the clipboard
+const defaultClipboardClear = 30 * time.Second
+
+var (
+	// ErrClipboardRequiresSingleKey is thrown if --clipboard is used to copy all keys
+	ErrClipboardRequiresSingleKey = errors.New("--clipboard requires a single key, specify the key to copy")
+
+	// ErrClipboardUnavailable is thrown if neither the system clipboard nor OSC52 can be used
+	ErrClipboardUnavailable = errors.New("no clipboard available")
+
+	// ErrClipboardUnsupportedValue is thrown if the value can't be represented as clipboard text
+	ErrClipboardUnsupportedValue = errors.New("value can't be copied to the clipboard")
+)
+
+// Clipboard copies values to a clipboard
+//
+// Read reports ok=false if the clipboard content can't be read back, e.g.
+// when copying through the terminal.
+type Clipboard interface {
+	Read() (content string, ok bool, err error)
+	Write(content string) error
+}
+
+// systemClipboard uses the clipboard of the desktop environment
+type systemClipboard struct{}
+
+// Read returns the current content of the system clipboard
+func (systemClipboard) Read() (string, bool, error) {
+	content, err := clipboard.ReadAll()
+	return content, err == nil, err
+}
+
+// Write replaces the content of the system clipboard
+func (systemClipboard) Write(content string) error {
+	return clipboard.WriteAll(content)
+}
+
+// osc52Clipboard copies through the terminal emulator using OSC52 escape sequences
+//
+// This works over SSH and without a display but the content can't be read back.
+type osc52Clipboard struct {
+	terminal io.Writer
+}
+
+// Read isn't supported by OSC52 without a terminal round trip
+func (osc52Clipboard) Read() (string, bool, error) {
+	return "", false, nil
+}
+
+// Write sends the content to the terminal clipboard, an empty content clears it
+func (c osc52Clipboard) Write(content string) error {
+	seq := osc52.New(content)
+	if content == "" {
+		seq = osc52.Clear()
+	}
+
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+
+	_, err := seq.WriteTo(c.terminal)
+	return err
+}
+
+// newClipboard selects the system clipboard or falls back to OSC52 on the terminal
+//
+// On Linux the system clipboard needs a display, without one OSC52 is used
+// if the given writer is a terminal.
+func newClipboard(terminal io.Writer) (Clipboard, error) {
+	headless := runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+	if !headless && !clipboard.Unsupported {
+		return systemClipboard{}, nil
+	}
+
+	if isTerminalWriter(terminal) {
+		return osc52Clipboard{terminal: terminal}, nil
+	}
+
+	reason := "no clipboard utility (xclip, xsel, wl-clipboard) was found"
+	if headless {
+		reason = "there's no display"
+	}
+
+	return nil, fmt.Errorf("%w: the system clipboard can't be used because %s and the output isn't a terminal for OSC52", ErrClipboardUnavailable, reason)
+}
+
+// isTerminalWriter reports whether the writer is a file attached to a terminal
+func isTerminalWriter(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// copyToClipboard copies the value and clears it again after the given duration
+//
+// The clipboard is only cleared if it still holds the value, unless it can't
+// be read back. An interrupt clears it right away. A zero duration keeps the
+// value in the clipboard.
+func copyToClipboard(cb Clipboard, key, value string, clearAfter time.Duration, errWriter io.Writer) error {
+	if err := cb.Write(value); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+
+	if clearAfter <= 0 {
+		_, _ = fmt.Fprintf(errWriter, "Copied %s to the clipboard\n", key)
+		return nil
+	}
+	_, _ = fmt.Fprintf(errWriter, "Copied %s to the clipboard, clearing in %s\n", key, clearAfter)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	timer := time.NewTimer(clearAfter)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	content, ok, err := cb.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read clipboard: %w", err)
+	}
+	if ok && content != value {
+		_, _ = fmt.Fprintln(errWriter, "Clipboard content changed, not clearing")
+		return nil
+	}
+
+	if err := cb.Write(""); err != nil {
+		return fmt.Errorf("failed to clear clipboard: %w", err)
+	}
+	_, _ = fmt.Fprintln(errWriter, "Cleared the clipboard")
+
+	return nil
+}
+
+// writeClipboard decodes a single value and copies it to the clipboard
+func writeClipboard(errWriter io.Writer, secret Secret, key string, opts ProcessOptions) error {
+	v, ok := secret.Data[key]
+	if !ok {
+		return ErrSecretKeyNotFound
+	}
+
+	kv, err := decodeKeyValue(secret, key, v, opts)
+	if err != nil {
+		return err
+	}
+
+	if kv.Encoding == encodingBase64 {
+		return fmt.Errorf("%w: %s holds binary data, use --raw to write it to a file", ErrClipboardUnsupportedValue, key)
+	}
+
+	return copyToClipboard(opts.Clipboard, key, kv.Value, opts.ClipboardClear, errWriter)
+}
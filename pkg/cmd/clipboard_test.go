@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClipboard records the writes and optionally pretends that another application replaced the content
+type fakeClipboard struct {
+	content    string
+	readable   bool
+	replacedBy string
+	writes     []string
+}
+
+func (f *fakeClipboard) Read() (string, bool, error) {
+	if f.replacedBy != "" {
+		return f.replacedBy, f.readable, nil
+	}
+	return f.content, f.readable, nil
+}
+
+func (f *fakeClipboard) Write(content string) error {
+	f.content = content
+	f.writes = append(f.writes, content)
+	return nil
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	tests := map[string]struct {
+		clearAfter time.Duration
+		clipboard  *fakeClipboard
+		wantErr    string
+		wantWrites []string
+	}{
+		"clears after timeout": {
+			clearAfter: time.Millisecond,
+			clipboard:  &fakeClipboard{readable: true},
+			wantErr:    "Copied password to the clipboard, clearing in 1ms\nCleared the clipboard\n",
+			wantWrites: []string{"hunter2", ""},
+		},
+		"keeps content copied by someone else": {
+			clearAfter: time.Millisecond,
+			clipboard:  &fakeClipboard{readable: true, replacedBy: "other"},
+			wantErr:    "Copied password to the clipboard, clearing in 1ms\nClipboard content changed, not clearing\n",
+			wantWrites: []string{"hunter2"},
+		},
+		"clears unreadable clipboard unconditionally": {
+			clearAfter: time.Millisecond,
+			clipboard:  &fakeClipboard{replacedBy: "other"},
+			wantErr:    "Copied password to the clipboard, clearing in 1ms\nCleared the clipboard\n",
+			wantWrites: []string{"hunter2", ""},
+		},
+		"zero timeout keeps content": {
+			clipboard:  &fakeClipboard{readable: true},
+			wantErr:    "Copied password to the clipboard\n",
+			wantWrites: []string{"hunter2"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var errBuf bytes.Buffer
+			err := copyToClipboard(tt.clipboard, "password", "hunter2", tt.clearAfter, &errBuf)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantErr, errBuf.String())
+			assert.Equal(t, tt.wantWrites, tt.clipboard.writes)
+		})
+	}
+}
+
+func TestProcessSecretClipboard(t *testing.T) {
+	secret := Secret{
+		Data: SecretData{
+			"keystore": base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0xfe}),
+			"password": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+		},
+		Metadata: Metadata{Name: "creds"},
+	}
+
+	tests := map[string]struct {
+		decodeAll  bool
+		secret     Secret
+		secretKey  string
+		wantErr    error
+		wantWrites []string
+	}{
+		"selected key": {
+			secret:     secret,
+			secretKey:  "password",
+			wantWrites: []string{"hunter2"},
+		},
+		"only key": {
+			secret:     Secret{Data: SecretData{"token": base64.StdEncoding.EncodeToString([]byte("abc"))}},
+			wantWrites: []string{"abc"},
+		},
+		"all keys": {
+			decodeAll: true,
+			secret:    secret,
+			wantErr:   ErrClipboardRequiresSingleKey,
+		},
+		"binary value": {
+			secret:    secret,
+			secretKey: "keystore",
+			wantErr:   ErrClipboardUnsupportedValue,
+		},
+		"missing key": {
+			secret:    secret,
+			secretKey: "missing",
+			wantErr:   ErrSecretKeyNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cb := &fakeClipboard{readable: true}
+			var outBuf bytes.Buffer
+			err := ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, &bytes.Buffer{}, tt.secret, ProcessOptions{
+				Clipboard:    cb,
+				DecodeAll:    tt.decodeAll,
+				OutputFormat: "text",
+				SecretKey:    tt.secretKey,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, cb.writes)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Empty(t, outBuf.String())
+			assert.Equal(t, tt.wantWrites, cb.writes)
+		})
+	}
+}
+
+func TestOSC52Clipboard(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	var termBuf bytes.Buffer
+	cb := osc52Clipboard{terminal: &termBuf}
+	assert.NoError(t, cb.Write("hunter2"))
+	assert.Equal(t, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte("hunter2"))+"\x07", termBuf.String())
+
+	// a buffer isn't a terminal, so without a display there's no clipboard to fall back to
+	if runtime.GOOS == "linux" {
+		t.Setenv("DISPLAY", "")
+		t.Setenv("WAYLAND_DISPLAY", "")
+		_, err := newClipboard(&termBuf)
+		assert.ErrorIs(t, err, ErrClipboardUnavailable)
+	}
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -54,3 +56,47 @@ func TestValidArgsFunctionLogic(t *testing.T) {
 		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
 	})
 }
+
+func TestCompletionFromManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "secrets.yaml")
+	assert.NoError(t, os.WriteFile(manifest, []byte(manifestMultiDoc), 0o600))
+
+	cmd := NewCmdViewSecret()
+	assert.NoError(t, cmd.Flags().Set("filename", manifest))
+
+	secrets, _ := cmd.ValidArgsFunction(cmd, []string{}, "")
+	assert.Equal(t, []string{"test", "string-data"}, secrets)
+
+	keys, _ := cmd.ValidArgsFunction(cmd, []string{"string-data"}, "")
+	assert.Equal(t, []string{"key1", "key2", "key3"}, keys)
+}
+
+func TestHelmReleaseCompletionFromManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "secrets.yaml")
+	assert.NoError(t, os.WriteFile(manifest, []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: sh.helm.release.v1.wordpress.v2
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: sh.helm.release.v1.wordpress.v1
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: sh.helm.release.v1.mysql.v1
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: unrelated
+`), 0o600))
+
+	cmd := newCmdHelmDiff()
+	assert.NoError(t, cmd.Flags().Set("filename", manifest))
+
+	releases, _ := getHelmReleases(cmd, nil, "")
+	assert.Equal(t, []string{"mysql", "wordpress"}, releases)
+}
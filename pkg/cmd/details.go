@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoDetailsView is thrown if there's no structured view for the type of the secret
+var ErrNoDetailsView = errors.New("no structured view available")
+
+// DetailsOptions holds the settings of the type-aware structured views
+type DetailsOptions struct {
+	// Mask hides passwords and tokens
+	Mask Masking
+	// Now is the reference time for any expiry calculations
+	Now time.Time
+	// Registry limits docker config views to a single registry host
+	Registry string
+}
+
+// secretDetails builds the type-aware structured view of a secret
+func secretDetails(secret Secret, opts DetailsOptions) (textRenderer, error) {
+	switch secret.Type {
+	case DockerCfg, DockerConfigJSON:
+		return parseDockerConfigDetails(secret, opts)
+	case ServiceAccountToken:
+		return parseServiceAccountTokenDetails(secret, opts.Now)
+	case SSHAuth:
+		return parseSSHKeyDetails(secret)
+	case TLS:
+		return parseTLSDetails(secret, opts.Now)
+	case Token:
+		return parseBootstrapTokenDetails(secret, opts)
+	}
+
+	// any other secret may still carry tokens, e.g. OIDC or API tokens in an Opaque secret
+	if details, ok := findJWTDetails(secret, opts.Now); ok {
+		return details, nil
+	}
+
+	return nil, fmt.Errorf("%w for secret type %q", ErrNoDetailsView, secret.Type)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// noNewlineMarker follows a last line without line ending like in diff and git
+const noNewlineMarker = "\\ No newline at end of file\n"
+
+// unifiedDiff returns the unified diff between two texts or an empty string if they're equal
+func unifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(from),
+		B:        splitLines(to),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  diffContextLines,
+	})
+	if err != nil {
+		// writing to a string builder doesn't fail
+		return ""
+	}
+
+	return diff
+}
+
+// splitLines splits a text into lines keeping their line endings
+//
+// A last line without line ending carries the marker, so it differs from the
+// same line with a line ending and the marker is printed below it.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if last := lines[len(lines)-1]; last == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] = last + "\n" + noNewlineMarker
+	}
+
+	return lines
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := map[string]struct {
+		from string
+		to   string
+		want string
+	}{
+		"equal": {
+			"a\nb\n",
+			"a\nb\n",
+			"",
+		},
+		"changed line": {
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		"added to empty": {
+			"",
+			"a\nb\n",
+			"--- from\n+++ to\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		"removed all": {
+			"a\n",
+			"",
+			"--- from\n+++ to\n@@ -1 +0,0 @@\n-a\n",
+		},
+		"separate hunks": {
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			"--- from\n+++ to\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		"merged hunks": {
+			"1\n2\n3\n4\n5\n6\n7\n",
+			"one\n2\n3\n4\n5\n6\nseven\n",
+			"--- from\n+++ to\n@@ -1,7 +1,7 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n-7\n+seven\n",
+		},
+		"missing trailing newline": {
+			"a\nb",
+			"a\nb\n",
+			"--- from\n+++ to\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		"both without trailing newline": {
+			"a\nb",
+			"a\nc",
+			"--- from\n+++ to\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, unifiedDiff("from", "to", tt.from, tt.to))
+		})
+	}
+}
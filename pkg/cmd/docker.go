@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	dockerCfgKey        = ".dockercfg"
+	dockerConfigJSONKey = ".dockerconfigjson"
+)
+
+// ErrRegistryNotFound is thrown if the docker config has no credentials for the requested registry
+var ErrRegistryNotFound = errors.New("no credentials found for registry")
+
+// dockerHubAliases are the registry hosts which all refer to Docker Hub
+var dockerHubAliases = map[string]bool{
+	"docker.io":               true,
+	"index.docker.io":         true,
+	"registry-1.docker.io":    true,
+	"registry.hub.docker.com": true,
+}
+
+// RegistryCredential represents the decoded credentials for a single registry
+type RegistryCredential struct {
+	AuthError     string `json:"authError,omitempty" yaml:"authError,omitempty"`
+	Email         string `json:"email,omitempty" yaml:"email,omitempty"`
+	IdentityToken string `json:"identityToken,omitempty" yaml:"identityToken,omitempty"`
+	Password      string `json:"password,omitempty" yaml:"password,omitempty"`
+	Registry      string `json:"registry" yaml:"registry"`
+	Username      string `json:"username,omitempty" yaml:"username,omitempty"`
+}
+
+// DockerConfigDetails represents the parsed view of a docker config secret
+type DockerConfigDetails struct {
+	Registries []RegistryCredential `json:"registries" yaml:"registries"`
+}
+
+// dockerAuthEntry is a single registry entry of a docker config file
+type dockerAuthEntry struct {
+	Auth          string `json:"auth"`
+	Email         string `json:"email"`
+	IdentityToken string `json:"identitytoken"`
+	Password      string `json:"password"`
+	Username      string `json:"username"`
+}
+
+// parseDockerConfigDetails decodes the registry credentials of a docker config secret
+//
+// The ~/.docker/config.json layout nests the registries under `auths`, the
+// legacy ~/.dockercfg layout of kubernetes.io/dockercfg secrets has them as
+// the top-level keys.
+func parseDockerConfigDetails(secret Secret, opts DetailsOptions) (DockerConfigDetails, error) {
+	var details DockerConfigDetails
+
+	key := dockerConfigJSONKey
+	if secret.Type == DockerCfg {
+		key = dockerCfgKey
+	}
+
+	raw, err := decodeRawKey(secret, key)
+	if err != nil {
+		return details, err
+	}
+
+	entries, err := parseDockerAuths(raw, key == dockerCfgKey)
+	if err != nil {
+		return details, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+
+	registries := make([]string, 0, len(entries))
+	for registry := range entries {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+
+	details.Registries = []RegistryCredential{}
+	for _, registry := range registries {
+		if opts.Registry != "" && registryHost(registry) != registryHost(opts.Registry) {
+			continue
+		}
+
+		cred := newRegistryCredential(registry, entries[registry])
+		cred.Password = opts.Mask.apply(cred.Password)
+		cred.IdentityToken = opts.Mask.apply(cred.IdentityToken)
+		details.Registries = append(details.Registries, cred)
+	}
+
+	if opts.Registry != "" && len(details.Registries) == 0 {
+		return details, fmt.Errorf("%w %q, available: %s", ErrRegistryNotFound, opts.Registry, strings.Join(registries, ", "))
+	}
+
+	return details, nil
+}
+
+// parseDockerAuths returns the registry entries of a docker config
+//
+// The entries are read from `auths` if present. Otherwise only the legacy
+// layout has entries at the top level, other configs like `credsStore` or
+// `credHelpers` without `auths` have none.
+func parseDockerAuths(raw []byte, legacy bool) (map[string]dockerAuthEntry, error) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+
+	entries := map[string]dockerAuthEntry{}
+	if auths, ok := config["auths"]; ok {
+		if err := json.Unmarshal(auths, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+
+	if !legacy {
+		return entries, nil
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// newRegistryCredential decodes the `auth` field and merges it with the explicit credentials
+func newRegistryCredential(registry string, entry dockerAuthEntry) RegistryCredential {
+	cred := RegistryCredential{
+		Email:         entry.Email,
+		IdentityToken: entry.IdentityToken,
+		Password:      entry.Password,
+		Registry:      registry,
+		Username:      entry.Username,
+	}
+
+	if entry.Auth == "" {
+		return cred
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		cred.AuthError = fmt.Sprintf("failed to decode auth: %v", err)
+		return cred
+	}
+
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		cred.AuthError = "auth is not in the format username:password"
+		return cred
+	}
+
+	if cred.Username == "" {
+		cred.Username = username
+	}
+	if cred.Password == "" {
+		cred.Password = password
+	}
+
+	return cred
+}
+
+// registryHost normalizes a registry server address to its host
+//
+// The scheme and path are dropped and all Docker Hub aliases map to docker.io.
+func registryHost(registry string) string {
+	host := strings.ToLower(registry)
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+
+	if dockerHubAliases[host] {
+		return "docker.io"
+	}
+
+	return host
+}
+
+// renderText outputs the registry credentials in a human readable form
+func (d DockerConfigDetails) renderText(outWriter io.Writer) error {
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+
+	for i, r := range d.Registries {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "%s\n", r.Registry)
+		_, _ = fmt.Fprintf(w, "  Username:\t%s\n", r.Username)
+		_, _ = fmt.Fprintf(w, "  Password:\t%s\n", r.Password)
+		if r.Email != "" {
+			_, _ = fmt.Fprintf(w, "  Email:\t%s\n", r.Email)
+		}
+		if r.IdentityToken != "" {
+			_, _ = fmt.Fprintf(w, "  Identity Token:\t%s\n", r.IdentityToken)
+		}
+		if r.AuthError != "" {
+			_, _ = fmt.Fprintf(w, "  Error:\t%s\n", r.AuthError)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDockerConfigDetails(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("robot:s3cr:et"))
+	configJSON := `{"auths":{"ghcr.io":{"auth":"` + auth + `","email":"robot@example.com"},` +
+		`"https://index.docker.io/v1/":{"username":"hub","password":"hubpass"},` +
+		`"registry.example.com":{"identitytoken":"refresh-token"},` +
+		`"broken.example.com":{"auth":"bm9jb2xvbg=="}}}`
+	legacy := `{"quay.io":{"auth":"` + auth + `","email":"robot@example.com"}}`
+
+	tests := map[string]struct {
+		secret  Secret
+		opts    DetailsOptions
+		want    []RegistryCredential
+		wantErr error
+	}{
+		"auths layout": {
+			secret: newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: configJSON}),
+			want: []RegistryCredential{
+				{Registry: "broken.example.com", AuthError: "auth is not in the format username:password"},
+				{Registry: "ghcr.io", Username: "robot", Password: "s3cr:et", Email: "robot@example.com"},
+				{Registry: "https://index.docker.io/v1/", Username: "hub", Password: "hubpass"},
+				{Registry: "registry.example.com", IdentityToken: "refresh-token"},
+			},
+		},
+		"legacy layout": {
+			secret: newTestSecret(DockerCfg, "default", "pull-secret", map[string]string{dockerCfgKey: legacy}),
+			want:   []RegistryCredential{{Registry: "quay.io", Username: "robot", Password: "s3cr:et", Email: "robot@example.com"}},
+		},
+		"credential helpers without auths": {
+			secret: newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: `{"credsStore":"desktop","credHelpers":{"gcr.io":"gcloud"}}`}),
+			want:   []RegistryCredential{},
+		},
+		"top-level registries need the legacy type": {
+			secret: newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: legacy}),
+			want:   []RegistryCredential{},
+		},
+		"filter registry": {
+			secret: newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: configJSON}),
+			opts:   DetailsOptions{Registry: "GHCR.io"},
+			want:   []RegistryCredential{{Registry: "ghcr.io", Username: "robot", Password: "s3cr:et", Email: "robot@example.com"}},
+		},
+		"filter docker hub alias": {
+			secret: newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: configJSON}),
+			opts:   DetailsOptions{Registry: "docker.io"},
+			want:   []RegistryCredential{{Registry: "https://index.docker.io/v1/", Username: "hub", Password: "hubpass"}},
+		},
+		"masked": {
+			secret: newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: configJSON}),
+			opts:   DetailsOptions{Mask: Masking{Mode: MaskFull}, Registry: "registry.example.com"},
+			want:   []RegistryCredential{{Registry: "registry.example.com", IdentityToken: maskedValue}},
+		},
+		"unknown registry": {
+			secret:  newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: configJSON}),
+			opts:    DetailsOptions{Registry: "gcr.io"},
+			wantErr: ErrRegistryNotFound,
+		},
+		"missing key": {
+			secret:  Secret{Data: SecretData{dockerCfgKey: "e30="}, Type: DockerConfigJSON},
+			wantErr: ErrSecretKeyNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDockerConfigDetails(tt.secret, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Registries)
+		})
+	}
+}
+
+func TestDockerConfigDetailsOutput(t *testing.T) {
+	secret := newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: `{"auths":{"ghcr.io":{"username":"robot","password":"hunter2","email":"robot@example.com"}}}`})
+
+	var buf bytes.Buffer
+	err := ProcessSecretWithOptions(&buf, &buf, nil, secret, ProcessOptions{Details: true, Mask: Masking{Mode: MaskFull}, OutputFormat: "text"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ghcr.io\n  Username:  robot\n  Password:  ********\n  Email:     robot@example.com\n", buf.String())
+
+	buf.Reset()
+	err = ProcessSecretWithOptions(&buf, &buf, nil, secret, ProcessOptions{Details: true, OutputFormat: "json"})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"password": "hunter2"`)
+}
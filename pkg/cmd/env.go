@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Output formats exporting the decoded values as environment variables
+const (
+	outputDockerEnvFile = "docker-env-file"
+	outputDotenv        = "dotenv"
+	outputEnv           = "env"
+)
+
+var (
+	// ErrInvalidEnvKey is thrown if a key can't be used as an environment variable name
+	ErrInvalidEnvKey = errors.New("keys are not valid environment variable names")
+
+	// ErrUnsupportedEnvValue is thrown if a value can't be represented in the env output format
+	ErrUnsupportedEnvValue = errors.New("value can't be represented")
+)
+
+// envKeyPattern matches the portable environment variable names defined by POSIX
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isEnvFormat reports whether the output format exports environment variables
+func isEnvFormat(outputFormat string) bool {
+	switch outputFormat {
+	case outputDockerEnvFile, outputDotenv, outputEnv:
+		return true
+	default:
+		return false
+	}
+}
+
+// outputEnvFormat outputs the decoded values as environment variable assignments
+//
+// All keys are validated before anything is written so that a partial
+// output never ends up being evaluated.
+func outputEnvFormat(outWriter io.Writer, sortedData []KeyValue, outputFormat string) error {
+	var invalid []string
+	for _, kv := range sortedData {
+		if !envKeyPattern.MatchString(kv.Key) {
+			invalid = append(invalid, kv.Key)
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidEnvKey, strings.Join(invalid, ", "))
+	}
+
+	var sb strings.Builder
+	for _, kv := range sortedData {
+		if kv.Encoding != "" {
+			return fmt.Errorf("%w in %s output: key %s holds binary data", ErrUnsupportedEnvValue, outputFormat, kv.Key)
+		}
+
+		switch outputFormat {
+		case outputEnv:
+			_, _ = fmt.Fprintf(&sb, "export %s=%s\n", kv.Key, shellQuote(kv.Value))
+		case outputDotenv:
+			_, _ = fmt.Fprintf(&sb, "%s=%s\n", kv.Key, dotenvQuote(kv.Value))
+		case outputDockerEnvFile:
+			// docker reads the value verbatim up to the end of the line, there's no quoting
+			if strings.ContainsAny(kv.Value, "\n\r") {
+				return fmt.Errorf("%w in %s output: key %s contains a line break", ErrUnsupportedEnvValue, outputFormat, kv.Key)
+			}
+			_, _ = fmt.Fprintf(&sb, "%s=%s\n", kv.Key, kv.Value)
+		}
+	}
+
+	if _, err := io.WriteString(outWriter, sb.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// shellQuote quotes a value for POSIX shells
+//
+// Single quotes preserve everything literally, embedded single quotes are
+// closed, escaped and reopened.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// dotenvQuote quotes a value for dotenv files as read by docker compose and direnv
+//
+// Single quotes are literal in both but can't contain single quotes or line
+// breaks, such values are double quoted with escapes and `$` is escaped to
+// prevent interpolation.
+func dotenvQuote(value string) string {
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"\n", `\n`,
+		"\r", `\r`,
+	)
+	return `"` + replacer.Replace(value) + `"`
+}
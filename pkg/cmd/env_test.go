@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputEnvFormat(t *testing.T) {
+	data := []KeyValue{
+		{Key: "API_KEY", Value: "abc123"},
+		{Key: "PASSWORD", Value: "it's $HOME `id`"},
+		{Key: "PEM", Value: "line1\nline2\n"},
+	}
+
+	tests := map[string]struct {
+		data    []KeyValue
+		format  string
+		want    string
+		wantErr error
+	}{
+		"env": {
+			data:   data,
+			format: outputEnv,
+			want:   "export API_KEY='abc123'\nexport PASSWORD='it'\\''s $HOME `id`'\nexport PEM='line1\nline2\n'\n",
+		},
+		"dotenv": {
+			data:   data,
+			format: outputDotenv,
+			want:   "API_KEY='abc123'\nPASSWORD=\"it's \\$HOME `id`\"\nPEM=\"line1\\nline2\\n\"\n",
+		},
+		"docker env file": {
+			data:   data[:2],
+			format: outputDockerEnvFile,
+			want:   "API_KEY=abc123\nPASSWORD=it's $HOME `id`\n",
+		},
+		"docker env file multiline": {
+			data:    data,
+			format:  outputDockerEnvFile,
+			wantErr: ErrUnsupportedEnvValue,
+		},
+		"invalid keys": {
+			data:    []KeyValue{{Key: "tls.crt", Value: "x"}, {Key: "1ST", Value: "x"}, {Key: "OK", Value: "x"}},
+			format:  outputEnv,
+			wantErr: ErrInvalidEnvKey,
+		},
+		"binary": {
+			data:    []KeyValue{{Encoding: encodingBase64, Key: "KEYSTORE", Value: "AAE="}},
+			format:  outputDotenv,
+			wantErr: ErrUnsupportedEnvValue,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := outputEnvFormat(&buf, tt.data, tt.format)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, buf.String())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestOutputEnvShellRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	value := "it's \"$HOME\" `id` \\ \n\ttab"
+	secret := Secret{Data: SecretData{"VALUE": base64.StdEncoding.EncodeToString([]byte(value))}, Type: Opaque}
+
+	var buf bytes.Buffer
+	err := ProcessSecretWithOptions(&buf, &bytes.Buffer{}, nil, secret, ProcessOptions{OutputFormat: outputEnv})
+	assert.NoError(t, err)
+
+	out, err := exec.Command("sh", "-c", buf.String()+`printf '%s' "$VALUE"`).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, value, string(out))
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	findKeysExample = `
+	# find the secrets holding a password in any namespace
+	%[1]s view-secret find-keys '*PASSWORD*' -i -A
+
+	# find all image pull secrets and TLS private keys
+	%[1]s view-secret find-keys .dockerconfigjson -A
+	%[1]s view-secret find-keys tls.key -n <ns>
+
+	# match the key names with a regular expression
+	%[1]s view-secret find-keys -E '^(aws|gcp)_' -i -A
+`
+
+	findKeysSummary = "Found %d matching keys in %d of %d secrets\n"
+)
+
+// ErrEmptyKeyPattern is thrown if there's no key pattern to search for
+var ErrEmptyKeyPattern = errors.New("no key pattern to search for")
+
+// FindKeysOpts is the struct holding the properties of the find-keys subcommand
+type FindKeysOpts struct {
+	sourceFlags
+
+	allNamespaces bool
+	ignoreCase    bool
+	labelSelector string
+	outputFormat  string
+	pattern       string
+	regexp        bool
+	source        SecretSource
+}
+
+// KeyMatch is a secret containing keys whose name matches the pattern
+type KeyMatch struct {
+	Keys      []string   `json:"keys" yaml:"keys"`
+	Name      string     `json:"name" yaml:"name"`
+	Namespace string     `json:"namespace" yaml:"namespace"`
+	Type      SecretType `json:"type" yaml:"type"`
+}
+
+// newCmdFindKeys creates the cobra command searching the key names of secrets
+func newCmdFindKeys() *cobra.Command {
+	res := &FindKeysOpts{}
+
+	cmd := &cobra.Command{
+		Args:         cobra.ExactArgs(1),
+		Example:      fmt.Sprintf(findKeysExample, "kubectl"),
+		Short:        "Find the secrets containing keys whose name matches a glob or regular expression without decoding any values",
+		SilenceUsage: true,
+		Use:          "find-keys <pattern>",
+		RunE: func(c *cobra.Command, args []string) error {
+			res.pattern = args[0]
+			return res.FindKeys(c)
+		},
+	}
+
+	res.sourceFlags.addFlags(cmd)
+	cmd.Flags().BoolVarP(&res.allNamespaces, "all-namespaces", "A", res.allNamespaces, "if true, searches secrets across all namespaces")
+	cmd.Flags().StringVarP(&res.labelSelector, "selector", "l", res.labelSelector, "only search secrets matching this label selector")
+	cmd.Flags().BoolVarP(&res.regexp, "extended-regexp", "E", res.regexp, "if true, interprets the pattern as a regular expression instead of a glob")
+	cmd.Flags().BoolVarP(&res.ignoreCase, "ignore-case", "i", res.ignoreCase, "if true, ignores case distinctions")
+	cmd.Flags().StringVarP(&res.outputFormat, "output", "o", "text", "output format: text, json, yaml")
+
+	return cmd
+}
+
+// FindKeys lists the secrets and reports the keys matching the pattern
+func (o *FindKeysOpts) FindKeys(cmd *cobra.Command) error {
+	pattern, err := compileKeyPattern(o.pattern, o.regexp, o.ignoreCase)
+	if err != nil {
+		return err
+	}
+
+	source := o.source
+	if source == nil {
+		if source, err = newSecretSourceFromFlags(cmd); err != nil {
+			return err
+		}
+	}
+
+	secretList, err := source.ListSecrets(contextFromCommand(cmd), ListOptions{
+		AllNamespaces: o.allNamespaces,
+		LabelSelector: o.labelSelector,
+	})
+	if err != nil {
+		return err
+	}
+
+	matches := findKeys(secretList.Items, pattern)
+
+	return ProcessFindKeys(cmd.OutOrStdout(), cmd.ErrOrStderr(), matches, len(secretList.Items), o.outputFormat)
+}
+
+// ProcessFindKeys outputs the matches and returns an ExitError with code 1 if there are none, just like grep
+func ProcessFindKeys(outWriter, errWriter io.Writer, matches []KeyMatch, secretCount int, outputFormat string) error {
+	var err error
+	switch outputFormat {
+	case "json":
+		err = writeJSON(outWriter, matches)
+	case "yaml":
+		err = writeYAML(outWriter, matches)
+	default:
+		err = renderKeyMatches(outWriter, matches)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	keyCount := 0
+	for _, m := range matches {
+		keyCount += len(m.Keys)
+	}
+	if _, err := fmt.Fprintf(errWriter, findKeysSummary, keyCount, len(matches), secretCount); err != nil {
+		return fmt.Errorf("failed to write to stderr: %w", err)
+	}
+
+	if len(matches) == 0 {
+		return &ExitError{Code: 1, Message: "no matching keys found"}
+	}
+
+	return nil
+}
+
+// renderKeyMatches outputs the matches as a table
+func renderKeyMatches(outWriter io.Writer, matches []KeyMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tSECRET\tTYPE\tKEYS")
+	for _, m := range matches {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Namespace, m.Name, m.Type, strings.Join(m.Keys, ","))
+	}
+
+	return w.Flush()
+}
+
+// compileKeyPattern compiles a glob or regular expression matching key names
+//
+// Globs match the whole key name, `*` matches any sequence of characters and
+// `?` a single character. Regular expressions match anywhere in the key name.
+func compileKeyPattern(pattern string, isRegexp, ignoreCase bool) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, ErrEmptyKeyPattern
+	}
+
+	expr := pattern
+	if !isRegexp {
+		expr = globToRegexp(pattern)
+	}
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	return re, nil
+}
+
+// globToRegexp converts a glob into an anchored regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+
+	return b.String()
+}
+
+// findKeys returns the secrets with keys matching the pattern sorted by namespace and name
+//
+// Only key names are inspected, the values are never decoded.
+func findKeys(secrets []Secret, pattern *regexp.Regexp) []KeyMatch {
+	matches := []KeyMatch{}
+	for _, secret := range secrets {
+		var keys []string
+		for k := range secret.Data {
+			if pattern.MatchString(k) {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+
+		matches = append(matches, KeyMatch{
+			Keys:      keys,
+			Name:      secret.Metadata.Name,
+			Namespace: secret.Metadata.Namespace,
+			Type:      secret.Type,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	return matches
+}
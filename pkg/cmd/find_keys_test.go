@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindKeys(t *testing.T) {
+	db := newTestSecret(Opaque, "apps", "db", map[string]string{"DB_PASSWORD": "x", "DB_USER": "app", "admin_password": "y"})
+	tls := newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: "crt", tlsKeyKey: "key"})
+	pull := newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: "{}"})
+	other := newTestSecret(Opaque, "apps", "config", map[string]string{"tls.key.bak": "z"})
+
+	tests := map[string]struct {
+		pattern    string
+		regexp     bool
+		ignoreCase bool
+		want       []KeyMatch
+		wantErr    error
+	}{
+		"glob": {
+			pattern: "*PASSWORD*",
+			want:    []KeyMatch{{Keys: []string{"DB_PASSWORD"}, Name: "db", Namespace: "apps", Type: Opaque}},
+		},
+		"glob ignoring case": {
+			pattern:    "*password",
+			ignoreCase: true,
+			want:       []KeyMatch{{Keys: []string{"DB_PASSWORD", "admin_password"}, Name: "db", Namespace: "apps", Type: Opaque}},
+		},
+		"exact key name": {
+			pattern: "tls.key",
+			want:    []KeyMatch{{Keys: []string{tlsKeyKey}, Name: tls.Metadata.Name, Namespace: tls.Metadata.Namespace, Type: TLS}},
+		},
+		"single character wildcard": {
+			pattern: ".dockerconfig?son",
+			want:    []KeyMatch{{Keys: []string{dockerConfigJSONKey}, Name: "pull-secret", Namespace: "default", Type: DockerConfigJSON}},
+		},
+		"regexp": {
+			pattern: `^tls\.`,
+			regexp:  true,
+			want: []KeyMatch{
+				{Keys: []string{"tls.key.bak"}, Name: "config", Namespace: "apps", Type: Opaque},
+				{Keys: []string{tlsCertKey, tlsKeyKey}, Name: tls.Metadata.Name, Namespace: tls.Metadata.Namespace, Type: TLS},
+			},
+		},
+		"no match": {
+			pattern: "token",
+			want:    []KeyMatch{},
+		},
+		"invalid regexp": {
+			pattern: "(",
+			regexp:  true,
+		},
+		"empty pattern": {
+			wantErr: ErrEmptyKeyPattern,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := compileKeyPattern(tt.pattern, tt.regexp, tt.ignoreCase)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if tt.want == nil {
+				assert.ErrorContains(t, err, "invalid pattern")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, findKeys([]Secret{tls, db, pull, other}, pattern))
+		})
+	}
+}
+
+func TestFindKeysCommand(t *testing.T) {
+	source := &fakeSource{namespace: "default", secrets: []Secret{
+		newTestSecret(Opaque, "apps", "db", map[string]string{"password": "leaked"}),
+		newTestSecret(Opaque, "default", "config", map[string]string{"host": "db"}),
+	}}
+
+	tests := map[string]struct {
+		opts     FindKeysOpts
+		wantCode int
+		wantOut  string
+	}{
+		"all namespaces": {opts: FindKeysOpts{allNamespaces: true, pattern: "pass*", outputFormat: "text"}, wantOut: "apps       db      Opaque  password"},
+		"yaml":           {opts: FindKeysOpts{allNamespaces: true, pattern: "host", outputFormat: "yaml"}, wantOut: "keys:\n    - host\n"},
+		"namespace":      {opts: FindKeysOpts{pattern: "pass*", outputFormat: "text"}, wantCode: 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+
+			opts := tt.opts
+			opts.source = source
+			err := opts.FindKeys(cmd)
+
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+			} else {
+				var exitErr *ExitError
+				if assert.ErrorAs(t, err, &exitErr) {
+					assert.Equal(t, tt.wantCode, exitErr.Code)
+				}
+			}
+			assert.Contains(t, outBuf.String(), tt.wantOut)
+			assert.NotContains(t, outBuf.String(), "leaked")
+		})
+	}
+}
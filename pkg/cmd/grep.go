@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	grepExample = `
+	# find the secrets containing a leaked credential, read from stdin to keep it out of the shell history
+	%[1]s view-secret grep -A < leaked.txt
+
+	# type the value without echoing it to the terminal
+	%[1]s view-secret grep -n <ns>
+
+	# search for a regular expression ignoring case
+	%[1]s view-secret grep -E -i 'akia[0-9a-z]{16}' -A
+`
+
+	grepSummary = "Found %d matches in %d of %d secrets\n"
+	grepPrompt  = "Value to search for: "
+
+	// minBase64NeedleLength is the minimum length of a literal to also search for its base64 encoding
+	minBase64NeedleLength = 6
+)
+
+var (
+	// ErrEmptyNeedle is thrown if there's nothing to search for
+	ErrEmptyNeedle = errors.New("no value to search for")
+
+	// ErrNeedleFromManifestStdin is thrown if stdin should provide both the manifests and the value to search for
+	ErrNeedleFromManifestStdin = errors.New("stdin can't provide both the manifests and the value to search for, pass the value as argument")
+)
+
+// GrepOpts is the struct holding the properties of the grep subcommand
+type GrepOpts struct {
+	sourceFlags
+
+	allNamespaces bool
+	ignoreCase    bool
+	labelSelector string
+	needle        string
+	outputFormat  string
+	regexp        bool
+	source        SecretSource
+}
+
+// GrepMatch is a key of a secret containing the value searched for
+//
+// Location tells where in the decoded value the match was found, e.g. in
+// the credentials of a registry or after removing nested encodings.
+type GrepMatch struct {
+	Key       string `json:"key" yaml:"key"`
+	Location  string `json:"location" yaml:"location"`
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+}
+
+// grepCandidate is a decoded representation of a value which is searched
+type grepCandidate struct {
+	location string
+	text     string
+}
+
+// grepMatcher reports whether a text contains the value searched for
+type grepMatcher struct {
+	base64Needles []string
+	pattern       *regexp.Regexp
+}
+
+// newCmdGrep creates the cobra command searching the decoded values of secrets
+func newCmdGrep() *cobra.Command {
+	res := &GrepOpts{}
+
+	cmd := &cobra.Command{
+		Args:         cobra.MaximumNArgs(1),
+		Example:      fmt.Sprintf(grepExample, "kubectl"),
+		Short:        "Find the secrets whose decoded values contain a literal or regular expression without printing the values",
+		SilenceUsage: true,
+		Use:          "grep [pattern]",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				res.needle = args[0]
+			}
+			return res.Grep(c)
+		},
+	}
+
+	res.sourceFlags.addFlags(cmd)
+	cmd.Flags().BoolVarP(&res.allNamespaces, "all-namespaces", "A", res.allNamespaces, "if true, searches secrets across all namespaces")
+	cmd.Flags().StringVarP(&res.labelSelector, "selector", "l", res.labelSelector, "only search secrets matching this label selector")
+	cmd.Flags().BoolVarP(&res.regexp, "extended-regexp", "E", res.regexp, "if true, interprets the pattern as a regular expression instead of a literal")
+	cmd.Flags().BoolVarP(&res.ignoreCase, "ignore-case", "i", res.ignoreCase, "if true, ignores case distinctions")
+	cmd.Flags().StringVarP(&res.outputFormat, "output", "o", "text", "output format: text, json, yaml")
+
+	return cmd
+}
+
+// Grep reads the value to search for and reports the secrets containing it
+//
+// Without a pattern argument the value is read from stdin, without echoing
+// it if stdin is a terminal, so it doesn't end up in the shell history.
+func (o *GrepOpts) Grep(cmd *cobra.Command) error {
+	needle := o.needle
+	if needle == "" {
+		for _, f := range o.filenames {
+			if f == stdinFilename {
+				return ErrNeedleFromManifestStdin
+			}
+		}
+
+		var err error
+		if needle, err = readNeedle(cmd.InOrStdin(), cmd.ErrOrStderr()); err != nil {
+			return err
+		}
+	}
+
+	matcher, err := newGrepMatcher(needle, o.regexp, o.ignoreCase)
+	if err != nil {
+		return err
+	}
+
+	source := o.source
+	if source == nil {
+		if source, err = newSecretSourceFromFlags(cmd); err != nil {
+			return err
+		}
+	}
+
+	secretList, err := source.ListSecrets(contextFromCommand(cmd), ListOptions{
+		AllNamespaces: o.allNamespaces,
+		LabelSelector: o.labelSelector,
+	})
+	if err != nil {
+		return err
+	}
+
+	matches := grepSecrets(secretList.Items, matcher, cmd.ErrOrStderr())
+
+	return ProcessGrep(cmd.OutOrStdout(), cmd.ErrOrStderr(), matches, len(secretList.Items), o.outputFormat)
+}
+
+// ProcessGrep outputs the matches and returns an ExitError with code 1 if there are none, just like grep
+func ProcessGrep(outWriter, errWriter io.Writer, matches []GrepMatch, secretCount int, outputFormat string) error {
+	var err error
+	switch outputFormat {
+	case "json":
+		err = writeJSON(outWriter, matches)
+	case "yaml":
+		err = writeYAML(outWriter, matches)
+	default:
+		err = renderGrepMatches(outWriter, matches)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	secrets := map[string]bool{}
+	for _, m := range matches {
+		secrets[m.Namespace+"/"+m.Name] = true
+	}
+	if _, err := fmt.Fprintf(errWriter, grepSummary, len(matches), len(secrets), secretCount); err != nil {
+		return fmt.Errorf("failed to write to stderr: %w", err)
+	}
+
+	if len(matches) == 0 {
+		return &ExitError{Code: 1, Message: "no matches found"}
+	}
+
+	return nil
+}
+
+// renderGrepMatches outputs the matches as a table
+func renderGrepMatches(outWriter io.Writer, matches []GrepMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tSECRET\tKEY\tLOCATION")
+	for _, m := range matches {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Namespace, m.Name, m.Key, m.Location)
+	}
+
+	return w.Flush()
+}
+
+// readNeedle reads the value to search for from stdin
+//
+// A single trailing line break is removed, e.g. from `echo` or a file.
+func readNeedle(in io.Reader, errWriter io.Writer) (string, error) {
+	var raw []byte
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		_, _ = fmt.Fprint(errWriter, grepPrompt)
+		var err error
+		raw, err = term.ReadPassword(int(f.Fd()))
+		_, _ = fmt.Fprintln(errWriter)
+		if err != nil {
+			return "", fmt.Errorf("failed to read value to search for: %w", err)
+		}
+	} else {
+		var err error
+		if raw, err = io.ReadAll(in); err != nil {
+			return "", fmt.Errorf("failed to read value to search for: %w", err)
+		}
+	}
+
+	needle := strings.TrimSuffix(strings.TrimSuffix(string(raw), "\n"), "\r")
+	if needle == "" {
+		return "", ErrEmptyNeedle
+	}
+
+	return needle, nil
+}
+
+// newGrepMatcher compiles the pattern
+//
+// Literals of a reasonable length are also searched for in base64 encoded
+// form to find them in nested manifests, e.g. the secrets rendered by a Helm
+// release.
+func newGrepMatcher(needle string, isRegexp, ignoreCase bool) (grepMatcher, error) {
+	if needle == "" {
+		return grepMatcher{}, ErrEmptyNeedle
+	}
+
+	expr := needle
+	if !isRegexp {
+		expr = regexp.QuoteMeta(needle)
+	}
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return grepMatcher{}, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	matcher := grepMatcher{pattern: pattern}
+	if !isRegexp && !ignoreCase && len(needle) >= minBase64NeedleLength {
+		matcher.base64Needles = base64Needles(needle)
+	}
+
+	return matcher, nil
+}
+
+// base64Needles returns the parts of the base64 encoding of the needle which don't depend on its position
+//
+// Base64 encodes 3 bytes at a time, so depending on the offset of the needle
+// in the encoded data there are three possible encodings. The characters
+// which mix bits of the needle with the surrounding bytes are cut off.
+func base64Needles(needle string) []string {
+	needles := make([]string, 0, 3)
+	for offset := range 3 {
+		data := append(bytes.Repeat([]byte{0}, offset), needle...)
+		encoded := base64.StdEncoding.EncodeToString(data)
+
+		start := (offset*8 + 5) / 6
+		end := len(data) * 8 / 6
+		needles = append(needles, encoded[start:end])
+	}
+	return needles
+}
+
+// match returns where the text contains the needle or false if it doesn't
+func (m grepMatcher) match(text string) (string, bool) {
+	if m.pattern.MatchString(text) {
+		return "", true
+	}
+
+	for _, n := range m.base64Needles {
+		if strings.Contains(text, n) {
+			return "base64", true
+		}
+	}
+
+	return "", false
+}
+
+// grepSecrets searches all keys of the secrets and returns the matches sorted by namespace, name and key
+//
+// Values which can't be decoded are reported on the error writer and otherwise skipped.
+func grepSecrets(secrets []Secret, matcher grepMatcher, errWriter io.Writer) []GrepMatch {
+	matches := []GrepMatch{}
+	for _, secret := range secrets {
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			candidates, err := grepCandidates(secret, key)
+			if err != nil {
+				_, _ = fmt.Fprintf(errWriter, "Skipping %s/%s %s: %v\n", secret.Metadata.Namespace, secret.Metadata.Name, key, err)
+				continue
+			}
+
+			for _, c := range candidates {
+				encoding, ok := matcher.match(c.text)
+				if !ok {
+					continue
+				}
+
+				location := c.location
+				if encoding != "" {
+					location += " (" + encoding + " encoded)"
+				}
+				matches = append(matches, GrepMatch{
+					Key:       key,
+					Location:  location,
+					Name:      secret.Metadata.Name,
+					Namespace: secret.Metadata.Namespace,
+				})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	return matches
+}
+
+// grepCandidates returns the type-aware decoded representations of a value
+//
+// The parts of Helm releases and the registry credentials of docker configs
+// come first so a match reports where exactly it was found, followed by the
+// decoded value and the value after removing nested encodings. Only the first
+// matching representation of a key is reported.
+func grepCandidates(secret Secret, key string) ([]grepCandidate, error) {
+	decoded, err := secret.Decode(secret.Data[key])
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []grepCandidate
+
+	if secret.Type == Helm && key == helmReleaseKey {
+		var release HelmRelease
+		if err := json.Unmarshal([]byte(decoded), &release); err == nil {
+			if values, err := yaml.Marshal(release.Config); err == nil {
+				candidates = append(candidates, grepCandidate{location: "helm values", text: string(values)})
+			}
+			candidates = append(candidates, grepCandidate{location: "helm manifest", text: release.Manifest})
+			for _, hook := range release.Hooks {
+				candidates = append(candidates, grepCandidate{location: "helm hook " + hook.Name, text: hook.Manifest})
+			}
+		}
+	}
+
+	if (secret.Type == DockerCfg || secret.Type == DockerConfigJSON) && (key == dockerCfgKey || key == dockerConfigJSONKey) {
+		if entries, err := parseDockerAuths([]byte(decoded), secret.Type == DockerCfg || key == dockerCfgKey); err == nil {
+			registries := make([]string, 0, len(entries))
+			for registry := range entries {
+				registries = append(registries, registry)
+			}
+			sort.Strings(registries)
+
+			for _, registry := range registries {
+				cred := newRegistryCredential(registry, entries[registry])
+				candidates = append(candidates,
+					grepCandidate{location: "registry " + registry + " username", text: cred.Username},
+					grepCandidate{location: "registry " + registry + " password", text: cred.Password},
+					grepCandidate{location: "registry " + registry + " identity token", text: cred.IdentityToken},
+				)
+			}
+		}
+	}
+
+	candidates = append(candidates, grepCandidate{location: "value", text: decoded})
+
+	if unwrapped, layers := unwrapValue(decoded, 0); len(layers) > 0 && unwrapped != decoded {
+		candidates = append(candidates, grepCandidate{location: "unwrapped " + formatLayers(layers), text: unwrapped})
+	}
+
+	return candidates, nil
+}
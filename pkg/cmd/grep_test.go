@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+const grepTestNeedle = "hunter2-leaked"
+
+func TestGrepSecrets(t *testing.T) {
+	leaked := base64.StdEncoding.EncodeToString([]byte(grepTestNeedle))
+	release := `{"name":"app","version":1,"config":{"db":{"password":"` + grepTestNeedle + `"}},"manifest":""}`
+	renderedRelease := `{"name":"app","version":1,"config":{},` +
+		`"manifest":"kind: Secret\ndata:\n  password: x` + leaked + `\n"}`
+	auth := base64.StdEncoding.EncodeToString([]byte("robot:" + grepTestNeedle))
+
+	plain := newTestSecret(Opaque, "apps", "db", map[string]string{"password": grepTestNeedle, "user": "app"})
+	nested := newTestSecret(Opaque, "apps", "api", map[string]string{"token": base64.StdEncoding.EncodeToString([]byte("HUNTER2-LEAKED"))})
+	other := newTestSecret(Opaque, "default", "unrelated", map[string]string{"password": "something else"})
+	helmValues := newHelmSecret(t, "sh.helm.release.v1.app.v1", release)
+	helmManifest := newHelmSecret(t, "sh.helm.release.v1.app.v2", renderedRelease)
+	pullSecret := newTestSecret(DockerConfigJSON, "default", "pull-secret", map[string]string{dockerConfigJSONKey: `{"auths":{"ghcr.io":{"auth":"` + auth + `"}}}`})
+	broken := Secret{Data: SecretData{"key": "not base64!"}, Metadata: Metadata{Name: "broken", Namespace: "apps"}, Type: Opaque}
+
+	tests := map[string]struct {
+		needle     string
+		regexp     bool
+		ignoreCase bool
+		secrets    []Secret
+		want       []string
+	}{
+		"literal": {
+			needle:  grepTestNeedle,
+			secrets: []Secret{other, plain},
+			want:    []string{"apps/db password value"},
+		},
+		"helm values": {
+			needle:  grepTestNeedle,
+			secrets: []Secret{helmValues},
+			want:    []string{"default/sh.helm.release.v1.app.v1 release helm values"},
+		},
+		"base64 encoded in helm manifest": {
+			needle:  grepTestNeedle,
+			secrets: []Secret{helmManifest},
+			want:    []string{"default/sh.helm.release.v1.app.v2 release helm manifest (base64 encoded)"},
+		},
+		"registry password": {
+			needle:  grepTestNeedle,
+			secrets: []Secret{pullSecret},
+			want:    []string{"default/pull-secret .dockerconfigjson registry ghcr.io password"},
+		},
+		"ignore case in nested encoding": {
+			needle:     grepTestNeedle,
+			ignoreCase: true,
+			secrets:    []Secret{nested, broken, plain},
+			want:       []string{"apps/api token unwrapped base64", "apps/db password value"},
+		},
+		"regexp": {
+			needle:  `hunter\d-\w+`,
+			regexp:  true,
+			secrets: []Secret{plain, other, pullSecret},
+			want:    []string{"apps/db password value", "default/pull-secret .dockerconfigjson registry ghcr.io password"},
+		},
+		"no match": {
+			needle:  "nothing to see",
+			secrets: []Secret{plain, other},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := newGrepMatcher(tt.needle, tt.regexp, tt.ignoreCase)
+			assert.NoError(t, err)
+
+			var got []string
+			for _, m := range grepSecrets(tt.secrets, matcher, &bytes.Buffer{}) {
+				got = append(got, m.Namespace+"/"+m.Name+" "+m.Key+" "+m.Location)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBase64Needles(t *testing.T) {
+	for prefix := range 6 {
+		data := strings.Repeat("x", prefix) + grepTestNeedle + "suffix"
+		encoded := base64.StdEncoding.EncodeToString([]byte(data))
+
+		matcher, err := newGrepMatcher(grepTestNeedle, false, false)
+		assert.NoError(t, err)
+
+		location, ok := matcher.match(encoded)
+		assert.True(t, ok, "prefix of %d bytes", prefix)
+		assert.Equal(t, "base64", location)
+	}
+
+	// short literals would match too many unrelated values
+	matcher, err := newGrepMatcher("abc", false, false)
+	assert.NoError(t, err)
+	assert.Empty(t, matcher.base64Needles)
+}
+
+func TestReadNeedle(t *testing.T) {
+	needle, err := readNeedle(strings.NewReader(grepTestNeedle+"\r\n"), &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, grepTestNeedle, needle)
+
+	_, err = readNeedle(strings.NewReader("\n"), &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrEmptyNeedle)
+}
+
+func TestGrepCommand(t *testing.T) {
+	source := &fakeSource{namespace: "default", secrets: []Secret{
+		newTestSecret(Opaque, "apps", "db", map[string]string{"password": grepTestNeedle}),
+		newTestSecret(Opaque, "default", "unrelated", map[string]string{"password": "something else"}),
+	}}
+
+	tests := map[string]struct {
+		opts       GrepOpts
+		stdin      string
+		wantCode   int
+		wantOut    string
+		wantErr    error
+		wantErrMsg string
+	}{
+		"needle from stdin":   {opts: GrepOpts{allNamespaces: true, outputFormat: "text"}, stdin: grepTestNeedle + "\n", wantOut: "apps       db      password  value"},
+		"json":                {opts: GrepOpts{allNamespaces: true, needle: grepTestNeedle, outputFormat: "json"}, wantOut: `"name": "db"`},
+		"no match":            {opts: GrepOpts{needle: grepTestNeedle, outputFormat: "text"}, wantCode: 1},
+		"empty stdin":         {opts: GrepOpts{}, wantErr: ErrEmptyNeedle},
+		"invalid regexp":      {opts: GrepOpts{needle: "(", regexp: true}, wantErrMsg: "invalid pattern"},
+		"stdin for manifests": {opts: GrepOpts{sourceFlags: sourceFlags{filenames: []string{stdinFilename}}}, wantErr: ErrNeedleFromManifestStdin},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(tt.stdin))
+
+			opts := tt.opts
+			opts.source = source
+			err := opts.Grep(cmd)
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantErrMsg != "":
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+			case tt.wantCode == 0:
+				assert.NoError(t, err)
+			default:
+				var exitErr *ExitError
+				if assert.ErrorAs(t, err, &exitErr) {
+					assert.Equal(t, tt.wantCode, exitErr.Code)
+				}
+			}
+			assert.Contains(t, outBuf.String(), tt.wantOut)
+			assert.NotContains(t, outBuf.String(), grepTestNeedle)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"slices"
+)
+
+// hmacPrefix marks digests which are keyed with a user provided HMAC key
+const hmacPrefix = "hmac-"
+
+// hashAlgorithms maps the supported --hash algorithms to their implementation
+var hashAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+var (
+	// ErrUnsupportedHash is thrown if the hash algorithm isn't supported
+	ErrUnsupportedHash = errors.New("unsupported hash algorithm")
+
+	// ErrEmptyHMACKey is thrown if the HMAC key file is empty
+	ErrEmptyHMACKey = errors.New("hmac key file is empty")
+
+	// ErrHashUnsupported is thrown if an option doesn't output values which could be hashed
+	ErrHashUnsupported = errors.New("not supported with --hash")
+)
+
+// hashAlgorithmNames returns the sorted names of the supported hash algorithms
+func hashAlgorithmNames() []string {
+	names := make([]string, 0, len(hashAlgorithms))
+	for name := range hashAlgorithms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// validateHash checks that the hash algorithm is supported
+func validateHash(algorithm string) error {
+	if _, ok := hashAlgorithms[algorithm]; algorithm != "" && !ok {
+		return fmt.Errorf("%w %q, must be one of %v", ErrUnsupportedHash, algorithm, hashAlgorithmNames())
+	}
+	return nil
+}
+
+// validateHashOptions rejects the options which don't output values that could be replaced by digests
+func (o ProcessOptions) validateHashOptions() error {
+	if o.Hash == "" {
+		return nil
+	}
+
+	unsupported := map[string]bool{
+		"--clipboard": o.Clipboard != nil,
+		"--details":   o.Details,
+		"--raw":       o.Raw,
+		"--to-dir":    o.ToDir != "",
+	}
+
+	flags := make([]string, 0, len(unsupported))
+	for flag, set := range unsupported {
+		if set {
+			flags = append(flags, flag)
+		}
+	}
+	slices.Sort(flags)
+
+	if len(flags) > 0 {
+		return fmt.Errorf("%s: %w", flags[0], ErrHashUnsupported)
+	}
+
+	return nil
+}
+
+// readHMACKey reads the exact bytes of the HMAC key file
+func readHMACKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hmac key: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEmptyHMACKey, path)
+	}
+
+	return key, nil
+}
+
+// digestValue returns the hex encoded digest of the value prefixed with the algorithm
+//
+// With an HMAC key the digest is keyed so it can't be brute forced by anyone
+// who doesn't know the key, e.g. for short passwords posted in a ticket.
+func digestValue(value []byte, algorithm string, hmacKey []byte) (string, error) {
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("%w %q, must be one of %v", ErrUnsupportedHash, algorithm, hashAlgorithmNames())
+	}
+
+	prefix := algorithm
+	h := newHash()
+	if len(hmacKey) > 0 {
+		prefix = hmacPrefix + algorithm
+		h = hmac.New(newHash, hmacKey)
+	}
+	h.Write(value)
+
+	return prefix + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
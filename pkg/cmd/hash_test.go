@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	hunter2SHA256     = "sha256:f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7"
+	hunter2HMACSHA256 = "hmac-sha256:a2b0dab1fbce37e9c8039810a1162e1490c1b547d1773b75ebbb5e7616930acb"
+)
+
+func TestDigestValue(t *testing.T) {
+	tests := map[string]struct {
+		algorithm string
+		hmacKey   []byte
+		value     []byte
+		want      string
+		wantErr   error
+	}{
+		"sha256":      {algorithm: "sha256", value: []byte("hunter2"), want: hunter2SHA256},
+		"sha512":      {algorithm: "sha512", value: []byte("admin"), want: "sha512:c7ad44cbad762a5da0a452f9e854fdc1e0e7a52a38015f23f3eab1d80b931dd472634dfac71cd34ebc35d16ab7fb8a90c81f975113d6c7538dc69dd8de9077ec"},
+		"binary":      {algorithm: "sha256", value: []byte{0x00, 0xff, 0xfe}, want: "sha256:d590f90f7944340fb253f0c59cb89fd41d4ec255ff246f524f8f7c94f0a233e5"},
+		"hmac":        {algorithm: "sha256", hmacKey: []byte("k3y"), value: []byte("hunter2"), want: hunter2HMACSHA256},
+		"unsupported": {algorithm: "md5", value: []byte("hunter2"), wantErr: ErrUnsupportedHash},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := digestValue(tt.value, tt.algorithm, tt.hmacKey)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadHMACKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	emptyFile := filepath.Join(dir, "empty")
+	assert.NoError(t, os.WriteFile(keyFile, []byte("k3y\n"), 0o600))
+	assert.NoError(t, os.WriteFile(emptyFile, nil, 0o600))
+
+	key, err := readHMACKey(keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("k3y\n"), key)
+
+	_, err = readHMACKey(emptyFile)
+	assert.ErrorIs(t, err, ErrEmptyHMACKey)
+
+	_, err = readHMACKey(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestProcessSecretHash(t *testing.T) {
+	secret := Secret{
+		Data: SecretData{
+			"config.json": base64.StdEncoding.EncodeToString([]byte(`{"password":"hunter2"}`)),
+			"password":    base64.StdEncoding.EncodeToString([]byte("hunter2")),
+		},
+		Metadata: Metadata{Name: "creds", Namespace: "default"},
+		Type:     Opaque,
+	}
+
+	tests := map[string]struct {
+		opts    ProcessOptions
+		want    string
+		wantErr error
+	}{
+		"text": {
+			opts: ProcessOptions{Hash: "sha256", OutputFormat: "text", SecretKey: "password"},
+			want: hunter2SHA256 + "\n",
+		},
+		"hmac": {
+			opts: ProcessOptions{HMACKey: []byte("k3y"), Hash: "sha256", OutputFormat: "text", SecretKey: "password"},
+			want: hunter2HMACSHA256 + "\n",
+		},
+		"query result": {
+			opts: ProcessOptions{Hash: "sha256", OutputFormat: "text", Query: ".password", SecretKey: "config.json"},
+			want: hunter2SHA256 + "\n",
+		},
+		"yaml": {
+			opts: ProcessOptions{DecodeAll: true, Hash: "sha256", OutputFormat: "yaml"},
+			want: `data:
+    - key: config.json
+      value: sha256:7881d759c44f0e45f22a9ec1075a2b26a80da5dea5e08eb791d84468c4388222
+    - key: password
+      value: ` + hunter2SHA256 + `
+name: creds
+namespace: default
+type: Opaque
+`,
+		},
+		"unsupported": {
+			opts:    ProcessOptions{Hash: "md5", OutputFormat: "text", SecretKey: "password"},
+			wantErr: ErrUnsupportedHash,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var outBuf bytes.Buffer
+			err := ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, nil, secret, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+}
+
+func TestProcessSecretHashStoredBytes(t *testing.T) {
+	config := `{"auths":{"ghcr.io":{"username":"app","password":"hunter2"}}}`
+	secret := Secret{
+		Data:     SecretData{".dockerconfigjson": base64.StdEncoding.EncodeToString([]byte(config))},
+		Metadata: Metadata{Name: "pull", Namespace: "default"},
+		Type:     DockerConfigJSON,
+	}
+
+	// the digest matches `base64 -d | sha256sum` of the stored value instead of the pretty-printed view
+	var outBuf bytes.Buffer
+	err := ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, nil, secret, ProcessOptions{Hash: "sha256", OutputFormat: "text"})
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("sha256:%x\n", sha256.Sum256([]byte(config))), outBuf.String())
+
+	// queries hash the extracted field
+	outBuf.Reset()
+	err = ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, nil, secret, ProcessOptions{Hash: "sha256", OutputFormat: "text", Query: `.auths["ghcr.io"].password`})
+	assert.NoError(t, err)
+	assert.Equal(t, hunter2SHA256+"\n", outBuf.String())
+}
+
+func TestHashUnsupported(t *testing.T) {
+	secret := newTestSecret(Opaque, "default", "creds", map[string]string{"password": "hunter2"})
+
+	tests := map[string]struct {
+		opts    ProcessOptions
+		wantErr string
+	}{
+		"details":   {opts: ProcessOptions{Details: true}, wantErr: "--details"},
+		"raw":       {opts: ProcessOptions{Raw: true, SecretKey: "password"}, wantErr: "--raw"},
+		"to-dir":    {opts: ProcessOptions{ToDir: t.TempDir()}, wantErr: "--to-dir"},
+		"clipboard": {opts: ProcessOptions{Clipboard: &fakeClipboard{}, SecretKey: "password"}, wantErr: "--clipboard"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var outBuf bytes.Buffer
+			opts := tt.opts
+			opts.Hash, opts.OutputFormat = "sha256", "text"
+			err := ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, nil, secret, opts)
+			assert.ErrorIs(t, err, ErrHashUnsupported)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, outBuf.String())
+		})
+	}
+
+	opts := CommandOpts{hash: "sha256", helmPart: "values", secretName: "creds", source: &fakeSource{secrets: []Secret{secret}}}
+	assert.ErrorIs(t, opts.Retrieve(&cobra.Command{}), ErrHashUnsupported)
+
+	opts = CommandOpts{clipboard: true, hash: "sha256", secretName: "creds", source: &fakeSource{secrets: []Secret{secret}}}
+	assert.ErrorIs(t, opts.Retrieve(&cobra.Command{}), ErrHashUnsupported)
+}
+
+func TestHashIsNotMasked(t *testing.T) {
+	secrets := []Secret{
+		newTestSecret(Opaque, "default", "creds", map[string]string{"password": "hunter2"}),
+		newTestSecret(Opaque, "other", "creds", map[string]string{"password": "hunter2"}),
+	}
+
+	var outBuf bytes.Buffer
+	err := ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, nil, secrets[0], ProcessOptions{Hash: "sha256", Mask: Masking{Mode: MaskFull}, OutputFormat: "text", SecretKey: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, hunter2SHA256+"\n", outBuf.String())
+
+	outBuf.Reset()
+	err = ProcessSecretsWithOptions(&outBuf, &bytes.Buffer{}, secrets, ProcessOptions{Hash: "sha256", Mask: Masking{Mode: MaskFull}, OutputFormat: "json"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(outBuf.String(), hunter2SHA256))
+	assert.NotContains(t, outBuf.String(), maskedValue)
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// helmReleaseKey is the data key holding the release in Helm secrets
+const helmReleaseKey = "release"
+
+// HelmPart represents a part of a Helm release that can be viewed on its own
+type HelmPart string
+
+const (
+	HelmPartChart          HelmPart = "chart"
+	HelmPartComputedValues HelmPart = "computed-values"
+	HelmPartHooks          HelmPart = "hooks"
+	HelmPartManifest       HelmPart = "manifest"
+	HelmPartNotes          HelmPart = "notes"
+	HelmPartRevision       HelmPart = "revision"
+	HelmPartStatus         HelmPart = "status"
+	HelmPartValues         HelmPart = "values"
+)
+
+// helmParts lists all supported Helm release parts
+var helmParts = []HelmPart{
+	HelmPartChart,
+	HelmPartComputedValues,
+	HelmPartHooks,
+	HelmPartManifest,
+	HelmPartNotes,
+	HelmPartRevision,
+	HelmPartStatus,
+	HelmPartValues,
+}
+
+var (
+	// ErrNotHelmRelease is thrown if a Helm release part is requested for a secret of another type
+	ErrNotHelmRelease = errors.New("secret is not a helm release")
+
+	// ErrUnknownHelmPart is thrown if the requested Helm release part doesn't exist
+	ErrUnknownHelmPart = errors.New("unknown helm release part")
+)
+
+// HelmRelease represents a Helm v3 release as stored in the release secret
+//
+// Only the fields needed for inspection are mapped, see
+// https://github.com/helm/helm/blob/main/pkg/release/release.go
+type HelmRelease struct {
+	Chart     HelmChart      `json:"chart" yaml:"chart"`
+	Config    map[string]any `json:"config" yaml:"config"`
+	Hooks     []HelmHook     `json:"hooks" yaml:"hooks"`
+	Info      HelmInfo       `json:"info" yaml:"info"`
+	Manifest  string         `json:"manifest" yaml:"manifest"`
+	Name      string         `json:"name" yaml:"name"`
+	Namespace string         `json:"namespace" yaml:"namespace"`
+	Version   int            `json:"version" yaml:"version"`
+}
+
+// HelmChart represents the chart a release was installed from
+type HelmChart struct {
+	Metadata map[string]any `json:"metadata" yaml:"metadata"`
+	Values   map[string]any `json:"values" yaml:"values"`
+}
+
+// HelmInfo represents the status information of a release
+type HelmInfo struct {
+	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
+	FirstDeployed string `json:"first_deployed,omitempty" yaml:"first_deployed,omitempty"`
+	LastDeployed  string `json:"last_deployed,omitempty" yaml:"last_deployed,omitempty"`
+	Notes         string `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Status        string `json:"status" yaml:"status"`
+}
+
+// HelmHook represents a hook defined by the chart of a release
+type HelmHook struct {
+	DeletePolicies []string `json:"delete_policies,omitempty" yaml:"delete_policies,omitempty"`
+	Events         []string `json:"events" yaml:"events"`
+	Kind           string   `json:"kind" yaml:"kind"`
+	Manifest       string   `json:"manifest" yaml:"manifest"`
+	Name           string   `json:"name" yaml:"name"`
+	Path           string   `json:"path" yaml:"path"`
+	Weight         int      `json:"weight" yaml:"weight"`
+}
+
+// HelmStatus is the condensed status view of a release
+type HelmStatus struct {
+	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
+	FirstDeployed string `json:"first_deployed,omitempty" yaml:"first_deployed,omitempty"`
+	LastDeployed  string `json:"last_deployed,omitempty" yaml:"last_deployed,omitempty"`
+	Name          string `json:"name" yaml:"name"`
+	Namespace     string `json:"namespace" yaml:"namespace"`
+	Revision      int    `json:"revision" yaml:"revision"`
+	Status        string `json:"status" yaml:"status"`
+}
+
+// DecodeHelmRelease decodes and parses the release stored in a Helm secret
+func (s Secret) DecodeHelmRelease() (HelmRelease, error) {
+	var release HelmRelease
+
+	if s.Type != Helm {
+		return release, fmt.Errorf("%w: %q has type %q", ErrNotHelmRelease, s.Metadata.Name, s.Type)
+	}
+
+	input, ok := s.Data[helmReleaseKey]
+	if !ok {
+		return release, fmt.Errorf("%w: %q has no %q key", ErrNotHelmRelease, s.Metadata.Name, helmReleaseKey)
+	}
+
+	decoded, err := s.decodeHelm(input)
+	if err != nil {
+		return release, fmt.Errorf("failed to decode helm release: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(decoded), &release); err != nil {
+		return release, fmt.Errorf("failed to parse helm release: %w", err)
+	}
+
+	return release, nil
+}
+
+// Part returns the requested part of the release
+//
+// Strings and integers are returned as-is, everything else is structured data.
+func (r HelmRelease) Part(part HelmPart) (any, error) {
+	switch part {
+	case HelmPartChart:
+		return r.Chart.Metadata, nil
+	case HelmPartComputedValues:
+		return coalesceValues(r.Chart.Values, r.Config), nil
+	case HelmPartHooks:
+		return r.Hooks, nil
+	case HelmPartManifest:
+		return r.Manifest, nil
+	case HelmPartNotes:
+		return r.Info.Notes, nil
+	case HelmPartRevision:
+		return r.Version, nil
+	case HelmPartStatus:
+		return HelmStatus{
+			Description:   r.Info.Description,
+			FirstDeployed: r.Info.FirstDeployed,
+			LastDeployed:  r.Info.LastDeployed,
+			Name:          r.Name,
+			Namespace:     r.Namespace,
+			Revision:      r.Version,
+			Status:        r.Info.Status,
+		}, nil
+	case HelmPartValues:
+		if r.Config == nil {
+			return map[string]any{}, nil
+		}
+		return r.Config, nil
+	default:
+		return nil, fmt.Errorf("%w %q, must be one of: %s", ErrUnknownHelmPart, part, strings.Join(helmPartNames(), ", "))
+	}
+}
+
+// ProcessHelmRelease outputs a single part of the Helm release stored in the secret
+func ProcessHelmRelease(outWriter io.Writer, secret Secret, part HelmPart, outputFormat string) error {
+	release, err := secret.DecodeHelmRelease()
+	if err != nil {
+		return err
+	}
+
+	value, err := release.Part(part)
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		return writeJSON(outWriter, value)
+	case "yaml":
+		return writeYAML(outWriter, value)
+	}
+
+	switch v := value.(type) {
+	case string:
+		_, err = fmt.Fprint(outWriter, ensureTrailingNewline(v))
+	case int:
+		_, err = fmt.Fprintf(outWriter, "%d\n", v)
+	case []HelmHook:
+		for _, hook := range v {
+			if _, err = fmt.Fprintf(outWriter, "---\n# Source: %s\n%s", hook.Path, ensureTrailingNewline(hook.Manifest)); err != nil {
+				break
+			}
+		}
+	default:
+		err = writeYAML(outWriter, v)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// coalesceValues merges the user supplied values on top of the chart defaults
+//
+// This mirrors `helm get values --all`: maps are merged recursively, any other
+// user supplied value replaces the default and a null value removes the key.
+func coalesceValues(defaults, overrides map[string]any) map[string]any {
+	res := make(map[string]any, len(defaults))
+	maps.Copy(res, defaults)
+
+	for k, v := range overrides {
+		if v == nil {
+			delete(res, k)
+			continue
+		}
+
+		overrideMap, isMap := v.(map[string]any)
+		defaultMap, defaultIsMap := res[k].(map[string]any)
+		if isMap && defaultIsMap {
+			res[k] = coalesceValues(defaultMap, overrideMap)
+			continue
+		}
+
+		res[k] = v
+	}
+
+	return res
+}
+
+// helmPartNames returns the names of all supported Helm release parts
+func helmPartNames() []string {
+	names := make([]string, 0, len(helmParts))
+	for _, p := range helmParts {
+		names = append(names, string(p))
+	}
+	return names
+}
+
+// ensureTrailingNewline appends a newline unless the string is empty or already ends with one
+func ensureTrailingNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	helmDiffExample = `
+	# show what changed in the last upgrade of a release
+	%[1]s view-secret helm-diff <release>
+
+	# compare two specific revisions
+	%[1]s view-secret helm-diff <release> --from 2 --to 5
+
+	# compare revisions of a release in a different namespace
+	%[1]s view-secret helm-diff <release> -n/--namespace <ns>
+`
+
+	// helmSecretPrefix is the name prefix of all secrets written by the Helm v3 secret storage driver
+	helmSecretPrefix = "sh.helm.release.v1."
+
+	// helmOwnerSelector matches the secrets written by the Helm secret storage driver, which labels them with the release name
+	helmOwnerSelector = "owner=helm"
+
+	helmDiffNoChanges = "No differences between revision %d and %d of release %q\n"
+)
+
+var (
+	// ErrHelmReleaseNotFound is thrown if no revision secrets exist for the release
+	ErrHelmReleaseNotFound = errors.New("no revisions found for helm release")
+
+	// ErrHelmRevisionNotFound is thrown if a requested revision doesn't exist
+	ErrHelmRevisionNotFound = errors.New("helm release revision not found")
+
+	// ErrHelmSingleRevision is thrown if there's nothing to compare against
+	ErrHelmSingleRevision = errors.New("helm release only has a single revision")
+)
+
+// HelmDiffOpts is the struct holding the properties of the helm-diff subcommand
+type HelmDiffOpts struct {
+	sourceFlags
+
+	fromRevision int
+	releaseName  string
+	source       SecretSource
+	toRevision   int
+}
+
+// helmRevision is a single revision secret of a Helm release
+type helmRevision struct {
+	revision int
+	secret   Secret
+}
+
+// newCmdHelmDiff creates the cobra command diffing two revisions of a Helm release
+func newCmdHelmDiff() *cobra.Command {
+	res := &HelmDiffOpts{}
+
+	cmd := &cobra.Command{
+		Args:         cobra.ExactArgs(1),
+		Example:      fmt.Sprintf(helmDiffExample, "kubectl"),
+		Short:        "Diff the values and rendered manifests of two revisions of a helm release",
+		SilenceUsage: true,
+		Use:          "helm-diff <release>",
+		RunE: func(c *cobra.Command, args []string) error {
+			res.releaseName = args[0]
+			return res.Diff(c)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return getHelmReleases(cmd, args, toComplete)
+		},
+	}
+
+	res.sourceFlags.addFlags(cmd)
+	cmd.Flags().IntVar(&res.fromRevision, "from", res.fromRevision, "revision to diff from, defaults to the revision before --to")
+	cmd.Flags().IntVar(&res.toRevision, "to", res.toRevision, "revision to diff to, defaults to the latest revision")
+
+	return cmd
+}
+
+// Diff discovers the revisions of the release and prints the diff between the selected ones
+func (h *HelmDiffOpts) Diff(cmd *cobra.Command) error {
+	source := h.source
+	if source == nil {
+		var err error
+		if source, err = newSecretSourceFromFlags(cmd); err != nil {
+			return err
+		}
+	}
+
+	secretList, err := source.ListSecrets(contextFromCommand(cmd), ListOptions{LabelSelector: helmOwnerSelector + ",name=" + h.releaseName})
+	if err != nil {
+		return err
+	}
+
+	revisions := helmRevisions(secretList.Items, h.releaseName)
+	from, to, err := selectHelmRevisions(revisions, h.releaseName, h.fromRevision, h.toRevision)
+	if err != nil {
+		return err
+	}
+
+	return ProcessHelmDiff(cmd.OutOrStdout(), cmd.ErrOrStderr(), from.secret, to.secret)
+}
+
+// ProcessHelmDiff prints the unified diff of the values and manifests of two Helm release secrets
+func ProcessHelmDiff(outWriter, errWriter io.Writer, fromSecret, toSecret Secret) error {
+	from, err := fromSecret.DecodeHelmRelease()
+	if err != nil {
+		return err
+	}
+
+	to, err := toSecret.DecodeHelmRelease()
+	if err != nil {
+		return err
+	}
+
+	fromValues, err := helmValuesYAML(from)
+	if err != nil {
+		return err
+	}
+
+	toValues, err := helmValuesYAML(to)
+	if err != nil {
+		return err
+	}
+
+	fromPrefix := fmt.Sprintf("%s.v%d", from.Name, from.Version)
+	toPrefix := fmt.Sprintf("%s.v%d", to.Name, to.Version)
+	diff := unifiedDiff(fromPrefix+"/values.yaml", toPrefix+"/values.yaml", fromValues, toValues) +
+		unifiedDiff(fromPrefix+"/manifest.yaml", toPrefix+"/manifest.yaml", from.Manifest, to.Manifest)
+
+	if diff == "" {
+		if _, err := fmt.Fprintf(errWriter, helmDiffNoChanges, from.Version, to.Version, to.Name); err != nil {
+			return fmt.Errorf("failed to write to stderr: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := fmt.Fprint(outWriter, diff); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// helmValuesYAML renders the user supplied values of a release as YAML
+func helmValuesYAML(release HelmRelease) (string, error) {
+	if len(release.Config) == 0 {
+		return "", nil
+	}
+
+	out, err := yaml.Marshal(release.Config)
+	if err != nil {
+		return "", fmt.Errorf("failed to render values of revision %d: %w", release.Version, err)
+	}
+
+	return string(out), nil
+}
+
+// helmRevisions returns the revision secrets of a release sorted by revision
+func helmRevisions(secrets []Secret, releaseName string) []helmRevision {
+	var revisions []helmRevision
+	for _, secret := range secrets {
+		name, revision, ok := parseHelmSecretName(secret.Metadata.Name)
+		if !ok || secret.Type != Helm || name != releaseName {
+			continue
+		}
+		revisions = append(revisions, helmRevision{revision: revision, secret: secret})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].revision < revisions[j].revision
+	})
+
+	return revisions
+}
+
+// selectHelmRevisions picks the revisions to compare, defaulting to the latest and the one before it
+func selectHelmRevisions(revisions []helmRevision, releaseName string, fromRevision, toRevision int) (helmRevision, helmRevision, error) {
+	if len(revisions) == 0 {
+		return helmRevision{}, helmRevision{}, fmt.Errorf("%w %q", ErrHelmReleaseNotFound, releaseName)
+	}
+
+	toIdx := len(revisions) - 1
+	if toRevision != 0 {
+		toIdx = indexOfHelmRevision(revisions, toRevision)
+		if toIdx < 0 {
+			return helmRevision{}, helmRevision{}, fmt.Errorf("%w: %q has no revision %d", ErrHelmRevisionNotFound, releaseName, toRevision)
+		}
+	}
+
+	fromIdx := toIdx - 1
+	if fromRevision != 0 {
+		fromIdx = indexOfHelmRevision(revisions, fromRevision)
+		if fromIdx < 0 {
+			return helmRevision{}, helmRevision{}, fmt.Errorf("%w: %q has no revision %d", ErrHelmRevisionNotFound, releaseName, fromRevision)
+		}
+	}
+
+	if fromIdx < 0 {
+		return helmRevision{}, helmRevision{}, fmt.Errorf("%w %q, specify --from to compare against", ErrHelmSingleRevision, releaseName)
+	}
+
+	return revisions[fromIdx], revisions[toIdx], nil
+}
+
+// indexOfHelmRevision returns the index of the revision or -1 if it doesn't exist
+func indexOfHelmRevision(revisions []helmRevision, revision int) int {
+	for i, r := range revisions {
+		if r.revision == revision {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseHelmSecretName splits a Helm secret name into the release name and revision
+func parseHelmSecretName(name string) (string, int, bool) {
+	trimmed, ok := strings.CutPrefix(name, helmSecretPrefix)
+	if !ok {
+		return "", 0, false
+	}
+
+	idx := strings.LastIndex(trimmed, ".v")
+	if idx <= 0 {
+		return "", 0, false
+	}
+
+	revision, err := strconv.Atoi(trimmed[idx+2:])
+	if err != nil {
+		return "", 0, false
+	}
+
+	return trimmed[:idx], revision, true
+}
+
+// getHelmReleases returns a list of helm release names for shell completion
+func getHelmReleases(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	source, err := newSecretSourceFromFlags(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	secretNames, err := source.ListSecretNames(contextFromCommand(cmd))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := map[string]bool{}
+	releases := []string{}
+	for _, secretName := range secretNames {
+		if name, _, ok := parseHelmSecretName(secretName); ok && !seen[name] {
+			seen[name] = true
+			releases = append(releases, name)
+		}
+	}
+	sort.Strings(releases)
+
+	return releases, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHelmSecretName(t *testing.T) {
+	tests := map[string]struct {
+		name         string
+		wantRelease  string
+		wantRevision int
+		wantOK       bool
+	}{
+		"valid":          {"sh.helm.release.v1.wordpress.v3", "wordpress", 3, true},
+		"dotted release": {"sh.helm.release.v1.my.app.v12", "my.app", 12, true},
+		"no prefix":      {"wordpress.v3", "", 0, false},
+		"no revision":    {"sh.helm.release.v1.wordpress", "", 0, false},
+		"bad revision":   {"sh.helm.release.v1.wordpress.vx", "", 0, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			release, revision, ok := parseHelmSecretName(tt.name)
+			assert.Equal(t, tt.wantRelease, release)
+			assert.Equal(t, tt.wantRevision, revision)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestHelmDiff(t *testing.T) {
+	source := &fakeSource{
+		secrets: []Secret{
+			newHelmSecret(t, "sh.helm.release.v1.wordpress.v1", helmReleaseJSON(1, 1, "6.0")),
+			newHelmSecret(t, "sh.helm.release.v1.wordpress.v3", helmReleaseJSON(3, 3, "6.1")),
+			newHelmSecret(t, "sh.helm.release.v1.wordpress.v2", helmReleaseJSON(2, 2, "6.0")),
+			newHelmSecret(t, "sh.helm.release.v1.single.v1", helmReleaseJSON(1, 1, "6.0")),
+			{Data: secretSingle, Metadata: Metadata{Name: "unrelated"}, Type: Opaque},
+			// only the labeled secrets of the storage driver are listed
+			{Data: secretSingle, Metadata: Metadata{Name: "sh.helm.release.v1.wordpress.v9"}, Type: Opaque},
+		},
+	}
+
+	tests := map[string]struct {
+		release    string
+		from, to   int
+		wantStdOut string
+		wantStdErr string
+		wantErr    error
+	}{
+		"latest vs previous": {
+			release: "wordpress",
+			wantStdOut: `--- wordpress.v2/values.yaml
++++ wordpress.v3/values.yaml
+@@ -1,4 +1,4 @@
+ debug: null
+ image:
+-    tag: "6.0"
+-replicas: 2
++    tag: "6.1"
++replicas: 3
+--- wordpress.v2/manifest.yaml
++++ wordpress.v3/manifest.yaml
+@@ -2,5 +2,5 @@
+ # Source: wordpress/templates/deployment.yaml
+ kind: Deployment
+ spec:
+-  replicas: 2
+-  image: wordpress:6.0
++  replicas: 3
++  image: wordpress:6.1
+`,
+		},
+		"explicit revisions": {
+			release: "wordpress",
+			from:    1,
+			to:      2,
+			wantStdOut: `--- wordpress.v1/values.yaml
++++ wordpress.v2/values.yaml
+@@ -1,4 +1,4 @@
+ debug: null
+ image:
+     tag: "6.0"
+-replicas: 1
++replicas: 2
+--- wordpress.v1/manifest.yaml
++++ wordpress.v2/manifest.yaml
+@@ -2,5 +2,5 @@
+ # Source: wordpress/templates/deployment.yaml
+ kind: Deployment
+ spec:
+-  replicas: 1
++  replicas: 2
+   image: wordpress:6.0
+`,
+		},
+		"same revision":     {release: "wordpress", from: 2, to: 2, wantStdErr: `No differences between revision 2 and 2 of release "wordpress"`},
+		"missing revision":  {release: "wordpress", to: 7, wantErr: ErrHelmRevisionNotFound},
+		"single revision":   {release: "single", wantErr: ErrHelmSingleRevision},
+		"release not found": {release: "ghost", wantErr: ErrHelmReleaseNotFound},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			stdOutBuf := bytes.Buffer{}
+			stdErrBuf := bytes.Buffer{}
+			cmd.SetOut(&stdOutBuf)
+			cmd.SetErr(&stdErrBuf)
+
+			opts := HelmDiffOpts{releaseName: tt.release, fromRevision: tt.from, toRevision: tt.to, source: source}
+			err := opts.Diff(cmd)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStdOut, stdOutBuf.String())
+			assert.True(t, strings.Contains(stdErrBuf.String(), tt.wantStdErr))
+		})
+	}
+}
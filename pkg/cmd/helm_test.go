@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// helmReleaseJSON returns a minimal helm release payload for the given revision
+func helmReleaseJSON(revision int, replicas int, image string) string {
+	return fmt.Sprintf(`{
+  "name": "wordpress",
+  "namespace": "default",
+  "version": %[1]d,
+  "info": {
+    "status": "deployed",
+    "description": "Upgrade complete",
+    "first_deployed": "2025-01-01T10:00:00Z",
+    "last_deployed": "2025-01-0%[1]dT10:00:00Z",
+    "notes": "Thanks for installing wordpress"
+  },
+  "chart": {
+    "metadata": {"name": "wordpress", "version": "1.0.%[1]d", "appVersion": "6.0"},
+    "values": {"replicas": 1, "image": {"repository": "wordpress", "tag": "latest"}, "debug": true}
+  },
+  "config": {"replicas": %[2]d, "image": {"tag": %[3]q}, "debug": null},
+  "manifest": "---\n# Source: wordpress/templates/deployment.yaml\nkind: Deployment\nspec:\n  replicas: %[2]d\n  image: wordpress:%[3]s\n",
+  "hooks": [
+    {"name": "wordpress-test", "kind": "Pod", "path": "wordpress/templates/tests/test.yaml", "manifest": "kind: Pod", "events": ["test"], "weight": 0}
+  ]
+}`, revision, replicas, image)
+}
+
+// newHelmSecret encodes a release payload the same way helm stores it in a secret
+func newHelmSecret(t *testing.T, name, release string) Secret {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(release))
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+
+	b64helm := base64.StdEncoding.EncodeToString(buf.Bytes())
+	b64k8s := base64.StdEncoding.EncodeToString([]byte(b64helm))
+
+	// the storage driver labels the secrets with the release name
+	metadata := Metadata{Name: name, Namespace: "default"}
+	if releaseName, _, ok := parseHelmSecretName(name); ok {
+		metadata.Labels = map[string]string{"name": releaseName, "owner": "helm"}
+	}
+
+	return Secret{
+		Data:     SecretData{helmReleaseKey: b64k8s},
+		Metadata: metadata,
+		Type:     Helm,
+	}
+}
+
+func TestProcessHelmRelease(t *testing.T) {
+	secret := newHelmSecret(t, "sh.helm.release.v1.wordpress.v2", helmReleaseJSON(2, 3, "6.1"))
+
+	tests := map[string]struct {
+		secret       Secret
+		part         HelmPart
+		outputFormat string
+		want         string
+		wantErr      error
+	}{
+		"values yaml": {
+			secret, HelmPartValues, "yaml",
+			"debug: null\nimage:\n    tag: \"6.1\"\nreplicas: 3\n",
+			nil,
+		},
+		"computed values": {
+			secret, HelmPartComputedValues, "text",
+			"image:\n    repository: wordpress\n    tag: \"6.1\"\nreplicas: 3\n",
+			nil,
+		},
+		"chart json": {
+			secret, HelmPartChart, "json",
+			"{\n  \"appVersion\": \"6.0\",\n  \"name\": \"wordpress\",\n  \"version\": \"1.0.2\"\n}\n",
+			nil,
+		},
+		"manifest": {
+			secret, HelmPartManifest, "text",
+			"---\n# Source: wordpress/templates/deployment.yaml\nkind: Deployment\nspec:\n  replicas: 3\n  image: wordpress:6.1\n",
+			nil,
+		},
+		"hooks": {
+			secret, HelmPartHooks, "text",
+			"---\n# Source: wordpress/templates/tests/test.yaml\nkind: Pod\n",
+			nil,
+		},
+		"notes": {
+			secret, HelmPartNotes, "text",
+			"Thanks for installing wordpress\n",
+			nil,
+		},
+		"revision": {
+			secret, HelmPartRevision, "text",
+			"2\n",
+			nil,
+		},
+		"status": {
+			secret, HelmPartStatus, "yaml",
+			"description: Upgrade complete\nfirst_deployed: \"2025-01-01T10:00:00Z\"\nlast_deployed: \"2025-01-02T10:00:00Z\"\nname: wordpress\nnamespace: default\nrevision: 2\nstatus: deployed\n",
+			nil,
+		},
+		"unknown part": {
+			secret, HelmPart("templates"), "text",
+			"",
+			ErrUnknownHelmPart,
+		},
+		"not a helm secret": {
+			Secret{Data: secretSingle, Type: Opaque}, HelmPartValues, "text",
+			"",
+			ErrNotHelmRelease,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := ProcessHelmRelease(&buf, tt.secret, tt.part, tt.outputFormat)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestCoalesceValues(t *testing.T) {
+	defaults := map[string]any{
+		"a": 1,
+		"b": map[string]any{"c": 2, "d": 3},
+		"e": "keep",
+	}
+	overrides := map[string]any{
+		"a": 10,
+		"b": map[string]any{"d": nil, "f": 4},
+		"e": nil,
+	}
+
+	got := coalesceValues(defaults, overrides)
+	assert.Equal(t, map[string]any{"a": 10, "b": map[string]any{"c": 2, "f": 4}}, got)
+	// the chart defaults must not be modified
+	assert.Equal(t, map[string]any{"c": 2, "d": 3}, defaults["b"])
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	serviceAccountTokenKey = "token"
+
+	// kubernetesClaimsKey holds the claims of projected service account tokens
+	kubernetesClaimsKey = "kubernetes.io"
+	// legacyClaimsPrefix prefixes the claims of the secret based service account tokens
+	legacyClaimsPrefix = "kubernetes.io/serviceaccount/"
+)
+
+// TokenStatus represents the validity of a token at a point in time
+type TokenStatus string
+
+const (
+	TokenStatusValid       TokenStatus = "valid"
+	TokenStatusExpired     TokenStatus = "expired"
+	TokenStatusNotYetValid TokenStatus = "not-yet-valid"
+)
+
+// ErrNoJWT is thrown if a value isn't a JSON Web Token
+var ErrNoJWT = errors.New("value is not a JWT")
+
+// jwtPattern matches the compact serialization of a signed JWT
+var jwtPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`)
+
+// KubernetesClaims represents the kubernetes specific claims of a service account token
+type KubernetesClaims struct {
+	Namespace          string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	NodeName           string `json:"nodeName,omitempty" yaml:"nodeName,omitempty"`
+	NodeUID            string `json:"nodeUID,omitempty" yaml:"nodeUID,omitempty"`
+	PodName            string `json:"podName,omitempty" yaml:"podName,omitempty"`
+	PodUID             string `json:"podUID,omitempty" yaml:"podUID,omitempty"`
+	SecretName         string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+	ServiceAccountName string `json:"serviceAccountName,omitempty" yaml:"serviceAccountName,omitempty"`
+	ServiceAccountUID  string `json:"serviceAccountUID,omitempty" yaml:"serviceAccountUID,omitempty"`
+}
+
+// JWTInfo represents the decoded header and claims of a JSON Web Token
+//
+// The signature is not verified.
+type JWTInfo struct {
+	Audience   []string          `json:"audience,omitempty" yaml:"audience,omitempty"`
+	Claims     map[string]any    `json:"claims" yaml:"claims"`
+	ExpiresAt  *time.Time        `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+	Header     map[string]any    `json:"header" yaml:"header"`
+	IssuedAt   *time.Time        `json:"issuedAt,omitempty" yaml:"issuedAt,omitempty"`
+	Issuer     string            `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Kubernetes *KubernetesClaims `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty"`
+	NotBefore  *time.Time        `json:"notBefore,omitempty" yaml:"notBefore,omitempty"`
+	Source     string            `json:"source" yaml:"source"`
+	Status     TokenStatus       `json:"status" yaml:"status"`
+	Subject    string            `json:"subject,omitempty" yaml:"subject,omitempty"`
+}
+
+// JWTDetails represents the parsed view of the tokens in a secret
+type JWTDetails struct {
+	Tokens []JWTInfo `json:"tokens" yaml:"tokens"`
+
+	// now is the reference time for the relative times in the text output
+	now time.Time
+}
+
+// parseServiceAccountTokenDetails decodes the token of a kubernetes.io/service-account-token secret
+func parseServiceAccountTokenDetails(secret Secret, now time.Time) (JWTDetails, error) {
+	details := JWTDetails{now: now}
+
+	raw, err := decodeRawKey(secret, serviceAccountTokenKey)
+	if err != nil {
+		return details, err
+	}
+
+	info, err := parseJWT(string(raw), serviceAccountTokenKey, now)
+	if err != nil {
+		return details, fmt.Errorf("failed to parse %s: %w", serviceAccountTokenKey, err)
+	}
+	details.Tokens = []JWTInfo{info}
+
+	return details, nil
+}
+
+// findJWTDetails decodes all values of a secret that look like a JWT
+func findJWTDetails(secret Secret, now time.Time) (JWTDetails, bool) {
+	details := JWTDetails{now: now}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw, err := decodeRawKey(secret, key)
+		if err != nil {
+			continue
+		}
+		if info, err := parseJWT(string(raw), key, now); err == nil {
+			details.Tokens = append(details.Tokens, info)
+		}
+	}
+
+	return details, len(details.Tokens) > 0
+}
+
+// parseJWT decodes the header and claims of a JWT in compact serialization
+func parseJWT(token, source string, now time.Time) (JWTInfo, error) {
+	info := JWTInfo{Source: source, Status: TokenStatusValid}
+
+	token = strings.TrimSpace(token)
+	if !jwtPattern.MatchString(token) {
+		return info, ErrNoJWT
+	}
+	parts := strings.Split(token, ".")
+
+	if err := decodeJWTSegment(parts[0], &info.Header); err != nil {
+		return info, fmt.Errorf("%w: invalid header: %w", ErrNoJWT, err)
+	}
+	if _, ok := info.Header["alg"]; !ok {
+		return info, fmt.Errorf("%w: header has no alg", ErrNoJWT)
+	}
+	if err := decodeJWTSegment(parts[1], &info.Claims); err != nil {
+		return info, fmt.Errorf("%w: invalid claims: %w", ErrNoJWT, err)
+	}
+
+	info.Issuer, _ = info.Claims["iss"].(string)
+	info.Subject, _ = info.Claims["sub"].(string)
+	info.Audience = audienceClaim(info.Claims["aud"])
+	info.ExpiresAt = timeClaim(info.Claims["exp"])
+	info.IssuedAt = timeClaim(info.Claims["iat"])
+	info.NotBefore = timeClaim(info.Claims["nbf"])
+	info.Kubernetes = kubernetesClaims(info.Claims)
+
+	switch {
+	case info.ExpiresAt != nil && !now.Before(*info.ExpiresAt):
+		info.Status = TokenStatusExpired
+	case info.NotBefore != nil && now.Before(*info.NotBefore):
+		info.Status = TokenStatusNotYetValid
+	}
+
+	return info, nil
+}
+
+// decodeJWTSegment decodes a base64url encoded JSON segment of a JWT
+func decodeJWTSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// audienceClaim returns the aud claim which is either a single string or a list
+func audienceClaim(v any) []string {
+	switch aud := v.(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		var res []string
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// timeClaim converts a NumericDate claim into a time
+func timeClaim(v any) *time.Time {
+	f, ok := v.(float64)
+	if !ok {
+		return nil
+	}
+	sec, frac := math.Modf(f)
+	t := time.Unix(int64(sec), int64(frac*1e9)).UTC()
+	return &t
+}
+
+// kubernetesClaims extracts the claims added by the kubernetes token issuer
+//
+// Projected tokens nest them under `kubernetes.io`, secret based tokens
+// use flat claims prefixed with `kubernetes.io/serviceaccount/`.
+func kubernetesClaims(claims map[string]any) *KubernetesClaims {
+	var k KubernetesClaims
+
+	if nested, ok := claims[kubernetesClaimsKey].(map[string]any); ok {
+		k.Namespace, _ = nested["namespace"].(string)
+		k.ServiceAccountName, k.ServiceAccountUID = nameAndUID(nested["serviceaccount"])
+		k.PodName, k.PodUID = nameAndUID(nested["pod"])
+		k.NodeName, k.NodeUID = nameAndUID(nested["node"])
+		k.SecretName, _ = nameAndUID(nested["secret"])
+	} else {
+		k.Namespace, _ = claims[legacyClaimsPrefix+"namespace"].(string)
+		k.SecretName, _ = claims[legacyClaimsPrefix+"secret.name"].(string)
+		k.ServiceAccountName, _ = claims[legacyClaimsPrefix+"service-account.name"].(string)
+		k.ServiceAccountUID, _ = claims[legacyClaimsPrefix+"service-account.uid"].(string)
+	}
+
+	if k == (KubernetesClaims{}) {
+		return nil
+	}
+
+	return &k
+}
+
+// nameAndUID reads the name and uid of a bound object reference claim
+func nameAndUID(v any) (string, string) {
+	ref, ok := v.(map[string]any)
+	if !ok {
+		return "", ""
+	}
+	name, _ := ref["name"].(string)
+	uid, _ := ref["uid"].(string)
+	return name, uid
+}
+
+// renderText outputs the token details in a human readable form
+func (d JWTDetails) renderText(outWriter io.Writer) error {
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+
+	for i, t := range d.Tokens {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "%s\n", t.Source)
+		_, _ = fmt.Fprintf(w, "  Status:\t%s\n", strings.ToUpper(string(t.Status)))
+		_, _ = fmt.Fprintf(w, "  Algorithm:\t%v (signature not verified)\n", t.Header["alg"])
+		if kid, ok := t.Header["kid"]; ok {
+			_, _ = fmt.Fprintf(w, "  Key ID:\t%v\n", kid)
+		}
+		if t.Issuer != "" {
+			_, _ = fmt.Fprintf(w, "  Issuer:\t%s\n", t.Issuer)
+		}
+		if t.Subject != "" {
+			_, _ = fmt.Fprintf(w, "  Subject:\t%s\n", t.Subject)
+		}
+		if len(t.Audience) > 0 {
+			_, _ = fmt.Fprintf(w, "  Audience:\t%s\n", strings.Join(t.Audience, ", "))
+		}
+		if t.IssuedAt != nil {
+			_, _ = fmt.Fprintf(w, "  Issued At:\t%s\n", relativeTime(*t.IssuedAt, d.now))
+		}
+		if t.NotBefore != nil {
+			_, _ = fmt.Fprintf(w, "  Not Before:\t%s\n", relativeTime(*t.NotBefore, d.now))
+		}
+		if t.ExpiresAt != nil {
+			_, _ = fmt.Fprintf(w, "  Expires At:\t%s\n", relativeTime(*t.ExpiresAt, d.now))
+		} else {
+			_, _ = fmt.Fprintf(w, "  Expires At:\tnever\n")
+		}
+
+		if k := t.Kubernetes; k != nil {
+			if k.Namespace != "" {
+				_, _ = fmt.Fprintf(w, "  Namespace:\t%s\n", k.Namespace)
+			}
+			if k.ServiceAccountName != "" {
+				_, _ = fmt.Fprintf(w, "  Service Account:\t%s\n", objectReference(k.ServiceAccountName, k.ServiceAccountUID))
+			}
+			if k.SecretName != "" {
+				_, _ = fmt.Fprintf(w, "  Bound Secret:\t%s\n", k.SecretName)
+			}
+			if k.PodName != "" {
+				_, _ = fmt.Fprintf(w, "  Bound Pod:\t%s\n", objectReference(k.PodName, k.PodUID))
+			}
+			if k.NodeName != "" {
+				_, _ = fmt.Fprintf(w, "  Bound Node:\t%s\n", objectReference(k.NodeName, k.NodeUID))
+			}
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// objectReference formats the name of an object together with its uid
+func objectReference(name, uid string) string {
+	if uid == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (uid %s)", name, uid)
+}
+
+// relativeTime formats a time together with the duration relative to now
+func relativeTime(t, now time.Time) string {
+	d := t.Sub(now)
+	if d < 0 {
+		return fmt.Sprintf("%s (%s ago)", t.Format(time.RFC3339), humanDuration(-d))
+	}
+	return fmt.Sprintf("%s (in %s)", t.Format(time.RFC3339), humanDuration(d))
+}
+
+// humanDuration formats a duration using its two most significant units
+func humanDuration(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+}
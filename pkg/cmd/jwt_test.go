@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+var jwtTestNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestJWT builds an unsigned token with the given claims
+func newTestJWT(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]any{"alg": "RS256", "kid": "key-1"})
+	assert.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	assert.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + ".c2ln"
+}
+
+func TestParseJWT(t *testing.T) {
+	projected := map[string]any{
+		"aud": []string{"https://kubernetes.default.svc"},
+		"exp": jwtTestNow.Add(90 * time.Minute).Unix(),
+		"iat": jwtTestNow.Add(-30 * time.Minute).Unix(),
+		"iss": "https://kubernetes.default.svc",
+		"nbf": jwtTestNow.Add(-30 * time.Minute).Unix(),
+		"sub": "system:serviceaccount:apps:web",
+		"kubernetes.io": map[string]any{
+			"namespace":      "apps",
+			"pod":            map[string]any{"name": "web-0", "uid": "pod-uid"},
+			"serviceaccount": map[string]any{"name": "web", "uid": "sa-uid"},
+		},
+	}
+	legacy := map[string]any{
+		"iss":                                    "kubernetes/serviceaccount",
+		"sub":                                    "system:serviceaccount:default:builder",
+		"kubernetes.io/serviceaccount/namespace": "default",
+		"kubernetes.io/serviceaccount/secret.name":          "builder-token",
+		"kubernetes.io/serviceaccount/service-account.name": "builder",
+		"kubernetes.io/serviceaccount/service-account.uid":  "sa-uid",
+	}
+
+	tests := map[string]struct {
+		token          string
+		wantStatus     TokenStatus
+		wantAudience   []string
+		wantKubernetes *KubernetesClaims
+		wantErr        error
+	}{
+		"projected": {
+			token:          newTestJWT(t, projected),
+			wantStatus:     TokenStatusValid,
+			wantAudience:   []string{"https://kubernetes.default.svc"},
+			wantKubernetes: &KubernetesClaims{Namespace: "apps", PodName: "web-0", PodUID: "pod-uid", ServiceAccountName: "web", ServiceAccountUID: "sa-uid"},
+		},
+		"legacy": {
+			token:          newTestJWT(t, legacy) + "\n",
+			wantStatus:     TokenStatusValid,
+			wantKubernetes: &KubernetesClaims{Namespace: "default", SecretName: "builder-token", ServiceAccountName: "builder", ServiceAccountUID: "sa-uid"},
+		},
+		"expired": {
+			token:        newTestJWT(t, map[string]any{"aud": "api", "exp": jwtTestNow.Add(-time.Hour).Unix()}),
+			wantStatus:   TokenStatusExpired,
+			wantAudience: []string{"api"},
+		},
+		"not yet valid": {
+			token:      newTestJWT(t, map[string]any{"nbf": jwtTestNow.Add(time.Hour).Unix()}),
+			wantStatus: TokenStatusNotYetValid,
+		},
+		"not a jwt": {
+			token:   "hunter2",
+			wantErr: ErrNoJWT,
+		},
+		"dotted but not a jwt": {
+			token:   "api.example.com",
+			wantErr: ErrNoJWT,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseJWT(tt.token, "token", jwtTestNow)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.Equal(t, tt.wantAudience, got.Audience)
+			assert.Equal(t, tt.wantKubernetes, got.Kubernetes)
+		})
+	}
+}
+
+func TestJWTDetailsOutput(t *testing.T) {
+	token := newTestJWT(t, map[string]any{
+		"exp": jwtTestNow.Add(-26 * time.Hour).Unix(),
+		"iss": "https://issuer.example.com",
+		"sub": "ci",
+	})
+
+	t.Run("service account token", func(t *testing.T) {
+		secret := Secret{
+			Data: SecretData{
+				"ca.crt":    base64.StdEncoding.EncodeToString([]byte("ca")),
+				"namespace": base64.StdEncoding.EncodeToString([]byte("default")),
+				"token":     base64.StdEncoding.EncodeToString([]byte(token)),
+			},
+			Type: ServiceAccountToken,
+		}
+
+		details, err := secretDetails(secret, DetailsOptions{Now: jwtTestNow})
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, outputFormattedSecret(&buf, secret, details, "text"))
+		assert.Equal(t, "token\n"+
+			"  Status:      EXPIRED\n"+
+			"  Algorithm:   RS256 (signature not verified)\n"+
+			"  Key ID:      key-1\n"+
+			"  Issuer:      https://issuer.example.com\n"+
+			"  Subject:     ci\n"+
+			"  Expires At:  2025-05-31T10:00:00Z (1d2h ago)\n", buf.String())
+	})
+
+	t.Run("opaque", func(t *testing.T) {
+		secret := Secret{
+			Data: SecretData{
+				"id_token": base64.StdEncoding.EncodeToString([]byte(token)),
+				"password": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+			},
+			Type: Opaque,
+		}
+
+		var buf bytes.Buffer
+		err := ProcessSecretWithOptions(&buf, &buf, nil, secret, ProcessOptions{Details: true, OutputFormat: "json"})
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `"source": "id_token"`)
+		assert.Contains(t, buf.String(), `"status": "expired"`)
+		assert.NotContains(t, buf.String(), "hunter2")
+	})
+}
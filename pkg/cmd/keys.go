@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// allKeysOption is the picker option selecting every key of the secret
+const allKeysOption = "all"
+
+var (
+	// ErrAllKeysExcluded is thrown if --exclude-keys leaves no key to view
+	ErrAllKeysExcluded = errors.New("all keys of the secret are excluded")
+
+	// ErrNoKeySelected is thrown if no key was selected in the key picker
+	ErrNoKeySelected = errors.New("select at least one key")
+)
+
+// selectedKeys returns the keys selected by the arguments and --keys without duplicates
+func (c *CommandOpts) selectedKeys() []string {
+	return uniqueKeys(append([]string{c.secretKey}, c.keys...))
+}
+
+// keySelection returns SecretKey and SecretKeys without duplicates
+func (o ProcessOptions) keySelection() []string {
+	return uniqueKeys(append([]string{o.SecretKey}, o.SecretKeys...))
+}
+
+// uniqueKeys returns the non-empty keys in their original order without duplicates
+func uniqueKeys(keys []string) []string {
+	var unique []string
+	for _, k := range keys {
+		if k != "" && !slices.Contains(unique, k) {
+			unique = append(unique, k)
+		}
+	}
+	return unique
+}
+
+// filterSecretData returns the data of the selected keys without the excluded ones
+//
+// Without a selection all keys are kept. Selected keys which don't exist in
+// the data are returned separately.
+func filterSecretData(data SecretData, selected, excluded []string) (SecretData, []string) {
+	filtered := make(SecretData, len(data))
+	var missing []string
+
+	if len(selected) == 0 {
+		for k, v := range data {
+			filtered[k] = v
+		}
+	} else {
+		for _, k := range selected {
+			v, ok := data[k]
+			if !ok {
+				missing = append(missing, k)
+				continue
+			}
+			filtered[k] = v
+		}
+	}
+
+	for _, k := range excluded {
+		delete(filtered, k)
+	}
+
+	return filtered, missing
+}
+
+// selectKeys lets the user pick the keys to view
+//
+// Without toggling any key the hovered one is viewed like with a single select
+// and "all" selects every key. With single only one key can be chosen, e.g.
+// for the clipboard.
+func selectKeys(outWriter io.Writer, inputReader io.Reader, secret Secret, keys []string, single bool) ([]string, error) {
+	programOpts := []tea.ProgramOption{tea.WithInput(inputReader), tea.WithOutput(outWriter)}
+
+	if single {
+		var selection string
+		err := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title(secretTitle).
+					Description(fmt.Sprintf(copyKeyDescription, len(keys), secret.Metadata.Name)).
+					Options(huh.NewOptions(keys...)...).
+					Value(&selection),
+			),
+		).WithProgramOptions(programOpts...).Run()
+		if err != nil {
+			return nil, err
+		}
+		return []string{selection}, nil
+	}
+
+	var selection []string
+	field := huh.NewMultiSelect[string]().
+		Title(secretTitle).
+		Description(fmt.Sprintf(secretDescription, len(keys), secret.Metadata.Name)).
+		Options(huh.NewOptions(append([]string{allKeysOption}, keys...)...)...).
+		Value(&selection)
+	if err := huh.NewForm(huh.NewGroup(field)).WithProgramOptions(programOpts...).Run(); err != nil {
+		return nil, err
+	}
+
+	if len(selection) == 0 {
+		hovered, ok := field.Hovered()
+		if !ok {
+			return nil, ErrNoKeySelected
+		}
+		selection = []string{hovered}
+	}
+
+	if slices.Contains(selection, allKeysOption) {
+		return keys, nil
+	}
+
+	return selection, nil
+}
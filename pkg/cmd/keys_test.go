@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectedKeys(t *testing.T) {
+	opts := CommandOpts{secretKey: "password", keys: []string{"username", "password", "host"}}
+	assert.Equal(t, []string{"password", "username", "host"}, opts.selectedKeys())
+
+	// a single key is always passed as a selection so that multiple secrets honor it as well
+	opts = CommandOpts{keys: []string{"username"}}
+	assert.Empty(t, opts.processOptions().SecretKey)
+	assert.Equal(t, []string{"username"}, opts.processOptions().SecretKeys)
+
+	assert.Equal(t, []string{"password", "username"}, ProcessOptions{SecretKey: "password", SecretKeys: []string{"username", "password"}}.keySelection())
+	assert.Empty(t, ProcessOptions{}.keySelection())
+}
+
+func TestProcessSecretKeySelection(t *testing.T) {
+	secret := newTestSecret(Opaque, "default", "db", map[string]string{
+		"ca.crt":   "ca",
+		"host":     "db.example.com",
+		"password": "s3cret",
+		"username": "app",
+	})
+
+	tests := map[string]struct {
+		opts     ProcessOptions
+		feedkeys string
+		want     string
+		wantErr  error
+	}{
+		"multiple keys": {
+			opts: ProcessOptions{SecretKeys: []string{"username", "password"}},
+			want: "password='s3cret'\nusername='app'\n",
+		},
+		"exclude keys": {
+			opts: ProcessOptions{DecodeAll: true, ExcludeKeys: []string{"ca.crt", "host"}},
+			want: "password='s3cret'\nusername='app'\n",
+		},
+		"exclude from selection": {
+			opts: ProcessOptions{SecretKeys: []string{"host", "password"}, ExcludeKeys: []string{"host"}},
+			want: "s3cret\n",
+		},
+		"missing key": {
+			opts:    ProcessOptions{SecretKeys: []string{"username", "token"}},
+			wantErr: ErrSecretKeyNotFound,
+		},
+		"all excluded": {
+			opts:    ProcessOptions{ExcludeKeys: []string{"ca.crt", "host", "password", "username"}},
+			wantErr: ErrAllKeysExcluded,
+		},
+		"raw requires single key": {
+			opts:    ProcessOptions{Raw: true, SecretKeys: []string{"username", "password"}},
+			wantErr: ErrRawRequiresSingleKey,
+		},
+		"clipboard requires single key": {
+			opts:    ProcessOptions{Clipboard: &fakeClipboard{}, SecretKeys: []string{"username", "password"}},
+			wantErr: ErrClipboardRequiresSingleKey,
+		},
+		"single selected key": {
+			opts: ProcessOptions{SecretKeys: []string{"password"}},
+			want: "s3cret\n",
+		},
+		"picker views all by default": {
+			opts:     ProcessOptions{ExcludeKeys: []string{"ca.crt"}},
+			feedkeys: "\r",
+			want:     "host='db.example.com'\npassword='s3cret'\nusername='app'\n",
+		},
+		"picker views hovered key": {
+			feedkeys: "\x1b[B\x1b[B\x1b[B\r",
+			want:     "s3cret\n",
+		},
+		"picker views toggled keys": {
+			// skip all and ca.crt, toggle host, skip password and toggle username
+			feedkeys: "\x1b[B\x1b[Bx\x1b[B\x1b[Bx\r",
+			want:     "host='db.example.com'\nusername='app'\n",
+		},
+		"picker toggles all keys": {
+			opts:     ProcessOptions{ExcludeKeys: []string{"ca.crt", "host"}},
+			feedkeys: "\x01\r",
+			want:     "password='s3cret'\nusername='app'\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := tt.opts
+			if opts.OutputFormat == "" {
+				opts.OutputFormat = "text"
+			}
+
+			var outBuf bytes.Buffer
+			err := ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, strings.NewReader(tt.feedkeys), secret, opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			// the picker renders to the same writer, the values follow it
+			if tt.feedkeys != "" {
+				assert.True(t, strings.HasSuffix(outBuf.String(), tt.want), outBuf.String())
+				return
+			}
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+}
+
+func TestProcessSecretKeyPickerClipboard(t *testing.T) {
+	secret := newTestSecret(Opaque, "default", "db", map[string]string{"password": "s3cret", "username": "app"})
+	cb := &fakeClipboard{readable: true}
+
+	// the picker offers single keys only, entering copies the hovered one
+	var outBuf, errBuf bytes.Buffer
+	err := ProcessSecretWithOptions(&outBuf, &errBuf, strings.NewReader("\r"), secret, ProcessOptions{Clipboard: cb, OutputFormat: "text"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"s3cret"}, cb.writes)
+	assert.Equal(t, "Copied password to the clipboard\n", errBuf.String())
+	assert.NotContains(t, outBuf.String(), allKeysOption)
+}
+
+func TestProcessSecretsKeySelection(t *testing.T) {
+	secrets := []Secret{
+		newTestSecret(Opaque, "apps", "db", map[string]string{"password": "s3cret", "username": "app"}),
+		newTestSecret(Opaque, "apps", "cache", map[string]string{"password": "other", "ca.crt": "ca"}),
+		newTestSecret(Opaque, "apps", "config", map[string]string{"host": "db"}),
+	}
+
+	var outBuf bytes.Buffer
+	err := ProcessSecretsWithOptions(&outBuf, &bytes.Buffer{}, secrets, ProcessOptions{
+		ExcludeKeys:  []string{"ca.crt"},
+		OutputFormat: "text",
+		SecretKeys:   []string{"password", "ca.crt"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "# apps/cache\npassword='other'\n\n# apps/db\npassword='s3cret'\n", outBuf.String())
+
+	err = ProcessSecretsWithOptions(&outBuf, &bytes.Buffer{}, secrets, ProcessOptions{SecretKeys: []string{"token"}})
+	assert.ErrorIs(t, err, ErrSecretKeyNotFound)
+}
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// stdinFilename is the filename used to read manifests from stdin
+const stdinFilename = "-"
+
+var (
+	// ErrManifestSecretNotFound is thrown if the requested secret isn't part of the provided manifests
+	ErrManifestSecretNotFound = errors.New("secret not found in manifests")
+
+	// ErrManifestSecretAmbiguous is thrown if the requested secret exists in multiple namespaces of the provided manifests
+	ErrManifestSecretAmbiguous = errors.New("secret found in multiple namespaces, use -n/--namespace to choose one")
+)
+
+// manifestExtensions are the file extensions considered when reading manifests from a directory
+var manifestExtensions = []string{".json", ".yaml", ".yml"}
+
+// secretManifest represents a secret as it is written in a manifest
+type secretManifest struct {
+	Secret
+	StringData map[string]string `json:"stringData"`
+}
+
+// manifestSource retrieves secrets from local manifest files instead of a cluster
+type manifestSource struct {
+	namespace string
+	secrets   []Secret
+}
+
+// newManifestSource reads all secrets from the given files and directories
+//
+// A filename of "-" reads from stdin. Directories are walked recursively and
+// only files with a .json, .yaml or .yml extension are considered. When a
+// namespace is given, only secrets in that namespace or without a namespace
+// are returned.
+func newManifestSource(filenames []string, stdin io.Reader, namespace string) (*manifestSource, error) {
+	source := &manifestSource{namespace: namespace}
+
+	for _, filename := range filenames {
+		if filename == stdinFilename {
+			secrets, err := ParseManifests(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse manifests from stdin: %w", err)
+			}
+			source.secrets = append(source.secrets, secrets...)
+			continue
+		}
+
+		paths, err := manifestPaths(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			secrets, err := parseManifestFile(path)
+			if err != nil {
+				return nil, err
+			}
+			source.secrets = append(source.secrets, secrets...)
+		}
+	}
+
+	return source, nil
+}
+
+// GetSecret returns the secret with the given name
+func (s *manifestSource) GetSecret(_ context.Context, name string) (Secret, error) {
+	var matches []Secret
+	for _, secret := range s.filtered() {
+		if secret.Metadata.Name == name {
+			matches = append(matches, secret)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Secret{}, fmt.Errorf("%w: %q", ErrManifestSecretNotFound, name)
+	case 1:
+		return matches[0], nil
+	}
+
+	for _, m := range matches[1:] {
+		if m.Metadata.Namespace != matches[0].Metadata.Namespace {
+			return Secret{}, fmt.Errorf("%w: %q", ErrManifestSecretAmbiguous, name)
+		}
+	}
+
+	// later documents win, just like applying them in order would
+	return matches[len(matches)-1], nil
+}
+
+// ListSecrets returns all secrets in the configured namespace or across all namespaces
+//
+// The selectors are matched locally, field selectors support the same fields
+// as the API server does for secrets: metadata.name, metadata.namespace and type.
+func (s *manifestSource) ListSecrets(_ context.Context, opts ListOptions) (SecretList, error) {
+	secrets := s.secrets
+	if !opts.AllNamespaces {
+		secrets = s.filtered()
+	}
+
+	if opts.LabelSelector == "" && opts.FieldSelector == "" {
+		return SecretList{Items: secrets}, nil
+	}
+
+	labelSelector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return SecretList{}, fmt.Errorf("invalid label selector: %w", err)
+	}
+
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return SecretList{}, fmt.Errorf("invalid field selector: %w", err)
+	}
+
+	items := []Secret{}
+	for _, secret := range secrets {
+		secretFields := fields.Set{
+			"metadata.name":      secret.Metadata.Name,
+			"metadata.namespace": secret.Metadata.Namespace,
+			"type":               string(secret.Type),
+		}
+		if labelSelector.Matches(labels.Set(secret.Metadata.Labels)) && fieldSelector.Matches(secretFields) {
+			items = append(items, secret)
+		}
+	}
+
+	return SecretList{Items: items}, nil
+}
+
+// ListSecretNames returns the names of the secrets in the configured namespace
+func (s *manifestSource) ListSecretNames(_ context.Context) ([]string, error) {
+	secrets := s.filtered()
+	names := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		names = append(names, secret.Metadata.Name)
+	}
+
+	return names, nil
+}
+
+// ListNamespaces returns the names of all namespaces referenced by the manifests
+func (s *manifestSource) ListNamespaces(_ context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	namespaces := []string{}
+	for _, secret := range s.secrets {
+		ns := secret.Metadata.Namespace
+		if ns != "" && !seen[ns] {
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// ListKeys returns the sorted data keys of the secret with the given name
+func (s *manifestSource) ListKeys(ctx context.Context, name string) ([]string, error) {
+	secret, err := s.GetSecret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// filtered returns the secrets matching the configured namespace
+func (s *manifestSource) filtered() []Secret {
+	if s.namespace == "" {
+		return s.secrets
+	}
+
+	secrets := []Secret{}
+	for _, secret := range s.secrets {
+		if secret.Metadata.Namespace == "" || secret.Metadata.Namespace == s.namespace {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return secrets
+}
+
+// manifestPaths returns the manifest files for a filename which may be a directory
+func manifestPaths(filename string) ([]string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if !info.IsDir() {
+		return []string{filename}, nil
+	}
+
+	var paths []string
+	err = filepath.WalkDir(filename, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && isManifestFile(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest directory: %w", err)
+	}
+
+	return paths, nil
+}
+
+// isManifestFile reports whether the file has a supported manifest extension
+func isManifestFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range manifestExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// parseManifestFile parses all secrets contained in a single manifest file
+func parseManifestFile(path string) ([]Secret, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	secrets, err := ParseManifests(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	return secrets, nil
+}
+
+// ParseManifests parses all secrets from a stream of JSON or (multi-document) YAML
+//
+// Single secrets as well as v1/List and SecretList objects are supported.
+// Objects of any other kind are skipped so that full application bundles
+// can be passed in as-is.
+func ParseManifests(r io.Reader) ([]Secret, error) {
+	var secrets []Secret
+
+	decoder := yaml.NewDecoder(r)
+	for {
+		var doc map[string]any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+
+		found, err := secretsFromObject(doc)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, found...)
+	}
+
+	return secrets, nil
+}
+
+// secretsFromObject extracts the secrets from a decoded manifest object
+func secretsFromObject(obj map[string]any) ([]Secret, error) {
+	kind, _ := obj["kind"].(string)
+
+	switch {
+	case kind == "Secret":
+		secret, err := secretFromManifest(obj)
+		if err != nil {
+			return nil, err
+		}
+		return []Secret{secret}, nil
+	case strings.HasSuffix(kind, "List"):
+		items, _ := obj["items"].([]any)
+
+		var secrets []Secret
+		for _, item := range items {
+			itemObj, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			// items of a SecretList don't need to carry their own kind
+			if _, ok := itemObj["kind"]; !ok && kind == "SecretList" {
+				itemObj["kind"] = "Secret"
+			}
+			found, err := secretsFromObject(itemObj)
+			if err != nil {
+				return nil, err
+			}
+			secrets = append(secrets, found...)
+		}
+		return secrets, nil
+	default:
+		return nil, nil
+	}
+}
+
+// secretFromManifest converts a secret manifest and merges stringData into data
+//
+// Just like the API server, stringData entries take precedence over data
+// entries with the same key. Secrets without a type are treated as Opaque.
+func secretFromManifest(obj map[string]any) (Secret, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	var manifest secretManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return Secret{}, fmt.Errorf("failed to parse secret manifest: %w", err)
+	}
+
+	secret := manifest.Secret
+	if secret.Type == "" {
+		secret.Type = Opaque
+	}
+
+	if len(manifest.StringData) > 0 {
+		data := make(SecretData, len(secret.Data)+len(manifest.StringData))
+		for k, v := range secret.Data {
+			data[k] = v
+		}
+		for k, v := range manifest.StringData {
+			data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+		secret.Data = data
+	}
+
+	return secret, nil
+}
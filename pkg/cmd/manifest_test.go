@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	manifestMultiDoc = `apiVersion: v1
+kind: Secret
+metadata:
+  name: test
+  namespace: default
+type: Opaque
+data:
+  key1: dmFsdWUx
+  key2: dmFsdWUy
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-secret
+data:
+  foo: bar
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: string-data
+stringData:
+  key2: overridden
+  key3: plain
+data:
+  key1: dmFsdWUx
+  key2: dmFsdWUy
+`
+
+	manifestList = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "v1",
+      "kind": "Secret",
+      "metadata": {"name": "test", "namespace": "default"},
+      "data": {"key1": "dmFsdWUx"},
+      "type": "Opaque"
+    },
+    {
+      "apiVersion": "v1",
+      "kind": "Secret",
+      "metadata": {"name": "test", "namespace": "another"},
+      "data": {"key1": "YW5vdGhlcg=="},
+      "type": "Opaque"
+    }
+  ]
+}
+`
+)
+
+func TestParseManifests(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    []Secret
+		wantErr bool
+	}{
+		"multi document with stringData": {
+			input: manifestMultiDoc,
+			want: []Secret{
+				{
+					Data:     SecretData{"key1": "dmFsdWUx", "key2": "dmFsdWUy"},
+					Metadata: Metadata{Name: "test", Namespace: "default"},
+					Type:     Opaque,
+				},
+				{
+					Data:     SecretData{"key1": "dmFsdWUx", "key2": "b3ZlcnJpZGRlbg==", "key3": "cGxhaW4="},
+					Metadata: Metadata{Name: "string-data"},
+					Type:     Opaque,
+				},
+			},
+		},
+		"v1 list": {
+			input: manifestList,
+			want: []Secret{
+				{
+					Data:     SecretData{"key1": "dmFsdWUx"},
+					Metadata: Metadata{Name: "test", Namespace: "default"},
+					Type:     Opaque,
+				},
+				{
+					Data:     SecretData{"key1": "YW5vdGhlcg=="},
+					Metadata: Metadata{Name: "test", Namespace: "another"},
+					Type:     Opaque,
+				},
+			},
+		},
+		"single json secret": {
+			input: validSecretJSON,
+			want: []Secret{
+				{
+					Data:     SecretData{"key1": "dmFsdWUxCg==", "key2": "dmFsdWUyCg=="},
+					Metadata: Metadata{Name: "test", Namespace: "default"},
+					Type:     Opaque,
+				},
+			},
+		},
+		"empty":   {input: "", want: nil},
+		"invalid": {input: "kind: [", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseManifests(strings.NewReader(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestManifestSource(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "secrets.yaml"), []byte(manifestMultiDoc), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "list.json"), []byte(manifestList), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not a manifest"), 0o600))
+
+	t.Run("directory", func(t *testing.T) {
+		source, err := newManifestSource([]string{dir}, nil, "")
+		assert.NoError(t, err)
+
+		list, err := source.ListSecrets(ctx, ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, list.Items, 4)
+
+		namespaces, err := source.ListNamespaces(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"another", "default"}, namespaces)
+
+		_, err = source.GetSecret(ctx, "test")
+		assert.ErrorIs(t, err, ErrManifestSecretAmbiguous)
+	})
+
+	t.Run("namespace filter", func(t *testing.T) {
+		source, err := newManifestSource([]string{dir}, nil, "another")
+		assert.NoError(t, err)
+
+		secret, err := source.GetSecret(ctx, "test")
+		assert.NoError(t, err)
+		assert.Equal(t, "another", secret.Metadata.Namespace)
+
+		keys, err := source.ListKeys(ctx, "string-data")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"key1", "key2", "key3"}, keys)
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		source, err := newManifestSource([]string{stdinFilename}, strings.NewReader(manifestMultiDoc), "")
+		assert.NoError(t, err)
+
+		_, err = source.GetSecret(ctx, "missing")
+		assert.ErrorIs(t, err, ErrManifestSecretNotFound)
+	})
+
+	t.Run("selectors", func(t *testing.T) {
+		source, err := newManifestSource([]string{stdinFilename}, strings.NewReader(`apiVersion: v1
+kind: Secret
+metadata:
+  name: payments-db
+  namespace: payments
+  labels:
+    app: payments
+type: kubernetes.io/basic-auth
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: payments-tls
+  namespace: payments
+  labels:
+    app: payments
+type: kubernetes.io/tls
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: checkout-db
+  namespace: checkout
+  labels:
+    app: checkout
+type: kubernetes.io/basic-auth
+`), "")
+		assert.NoError(t, err)
+
+		list, err := source.ListSecrets(ctx, ListOptions{LabelSelector: "app=payments"})
+		assert.NoError(t, err)
+		assert.Len(t, list.Items, 2)
+		assert.Equal(t, map[string]string{"app": "payments"}, list.Items[0].Metadata.Labels)
+
+		list, err = source.ListSecrets(ctx, ListOptions{FieldSelector: "type=kubernetes.io/basic-auth", LabelSelector: "app in (payments, checkout)"})
+		assert.NoError(t, err)
+		assert.Len(t, list.Items, 2)
+
+		list, err = source.ListSecrets(ctx, ListOptions{FieldSelector: "metadata.namespace=checkout,metadata.name!=checkout-db"})
+		assert.NoError(t, err)
+		assert.Empty(t, list.Items)
+
+		_, err = source.ListSecrets(ctx, ListOptions{LabelSelector: "app in ("})
+		assert.ErrorContains(t, err, "invalid label selector")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := newManifestSource([]string{filepath.Join(dir, "missing.yaml")}, nil, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewCmdViewSecretFromManifest(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		stdin   string
+		want    string
+		wantErr error
+	}{
+		"all keys":          {args: []string{"test", "-f", "-", "--all"}, stdin: manifestMultiDoc, want: "key1='value1'\nkey2='value2'\n"},
+		"stringData key":    {args: []string{"string-data", "key2", "-f", "-"}, stdin: manifestMultiDoc, want: "overridden\n"},
+		"namespaced":        {args: []string{"test", "-f", "-", "-n", "another", "-q"}, stdin: manifestList, want: "another\n"},
+		"secret not found":  {args: []string{"missing", "-f", "-"}, stdin: manifestMultiDoc, wantErr: errors.New(`secret not found in manifests: "missing"`)},
+		"no secrets in doc": {args: []string{"-f", "-"}, stdin: "kind: ConfigMap\n", wantErr: ErrNoSecretFound},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewCmdViewSecret()
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(tt.stdin))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.wantErr != nil {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantErr.Error(), err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+}
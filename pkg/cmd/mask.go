@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+const (
+	// defaultMaskChars is the number of characters revealed at both ends of a partially masked value
+	defaultMaskChars = 4
+
+	// maskedValue replaces sensitive values if masking is enabled
+	maskedValue = "********"
+)
+
+// MaskMode controls how much of a value is revealed
+type MaskMode string
+
+const (
+	MaskNone    MaskMode = ""
+	MaskFull    MaskMode = "full"
+	MaskLength  MaskMode = "length"
+	MaskPartial MaskMode = "partial"
+)
+
+// ErrInvalidMaskMode is thrown if the mask mode isn't supported
+var ErrInvalidMaskMode = errors.New("invalid mask mode")
+
+// maskModeNames returns the supported mask modes for help texts and shell completion
+func maskModeNames() []string {
+	return []string{string(MaskFull), string(MaskPartial), string(MaskLength)}
+}
+
+// Masking holds the mask mode and the number of characters revealed in partial mode
+type Masking struct {
+	Chars int
+	Mode  MaskMode
+}
+
+// validate checks that the mask mode is supported
+func (m Masking) validate() error {
+	if m.Mode != MaskNone && !slices.Contains(maskModeNames(), string(m.Mode)) {
+		return fmt.Errorf("%w %q, must be one of %v", ErrInvalidMaskMode, m.Mode, maskModeNames())
+	}
+	if m.Chars < 0 {
+		return fmt.Errorf("%w: --mask-chars must not be negative", ErrInvalidMaskMode)
+	}
+	return nil
+}
+
+// apply hides a non-empty value according to the mask mode
+//
+// Partial mode only reveals the ends of values which are more than twice as
+// long as the revealed characters, shorter ones are masked fully.
+func (m Masking) apply(v string) string {
+	if v == "" {
+		return ""
+	}
+
+	runes := []rune(v)
+	switch m.Mode {
+	case MaskNone:
+		return v
+	case MaskLength:
+		return fmt.Sprintf("[%d characters]", len(runes))
+	case MaskPartial:
+		if m.Chars > 0 && len(runes) > 2*m.Chars {
+			return string(runes[:m.Chars]) + maskedValue + string(runes[len(runes)-m.Chars:])
+		}
+	}
+
+	return maskedValue
+}
+
+// applyKeyValue masks a decoded value
+//
+// Binary values are never partially revealed, their length is counted in bytes.
+func (m Masking) applyKeyValue(kv KeyValue) KeyValue {
+	if m.Mode == MaskNone || kv.Encoding != encodingBase64 {
+		kv.Value = m.apply(kv.Value)
+		return kv
+	}
+
+	kv.Encoding = ""
+	if m.Mode != MaskLength {
+		kv.Value = maskedValue
+		return kv
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		kv.Value = maskedValue
+		return kv
+	}
+	kv.Value = fmt.Sprintf("[%d bytes]", len(raw))
+
+	return kv
+}
+
+// maskKeyValues masks all values except those of the revealed keys
+func maskKeyValues(data []KeyValue, m Masking, revealed []string) []KeyValue {
+	if m.Mode == MaskNone {
+		return data
+	}
+
+	masked := make([]KeyValue, 0, len(data))
+	for _, kv := range data {
+		if slices.Contains(revealed, kv.Key) {
+			masked = append(masked, kv)
+			continue
+		}
+		masked = append(masked, m.applyKeyValue(kv))
+	}
+
+	return masked
+}
+
+// selectRevealKeys lets the user toggle which of the selected keys are shown in plaintext
+func selectRevealKeys(outWriter io.Writer, inputReader io.Reader, keys []string) ([]string, error) {
+	var revealed []string
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title(revealTitle).
+				Description(revealDescription).
+				Options(huh.NewOptions(keys...)...).
+				Value(&revealed),
+		),
+	).WithProgramOptions(tea.WithInput(inputReader), tea.WithOutput(outWriter)).Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user selection: %w", err)
+	}
+
+	return revealed, nil
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingApply(t *testing.T) {
+	tests := map[string]struct {
+		masking Masking
+		value   string
+		want    string
+	}{
+		"none":                   {masking: Masking{}, value: "hunter2", want: "hunter2"},
+		"full":                   {masking: Masking{Mode: MaskFull}, value: "hunter2", want: maskedValue},
+		"empty stays empty":      {masking: Masking{Mode: MaskFull}, value: "", want: ""},
+		"length":                 {masking: Masking{Mode: MaskLength}, value: "pässwörd", want: "[8 characters]"},
+		"partial":                {masking: Masking{Chars: 4, Mode: MaskPartial}, value: "ghp_abcdefghijklmnop1234", want: "ghp_" + maskedValue + "1234"},
+		"partial multibyte":      {masking: Masking{Chars: 1, Mode: MaskPartial}, value: "äbcö", want: "ä" + maskedValue + "ö"},
+		"partial short value":    {masking: Masking{Chars: 4, Mode: MaskPartial}, value: "hunter2", want: maskedValue},
+		"partial without chars":  {masking: Masking{Mode: MaskPartial}, value: "hunter2", want: maskedValue},
+		"partial exactly double": {masking: Masking{Chars: 2, Mode: MaskPartial}, value: "abcd", want: maskedValue},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.masking.apply(tt.value))
+		})
+	}
+}
+
+func TestMaskingValidate(t *testing.T) {
+	assert.NoError(t, Masking{}.validate())
+	assert.NoError(t, Masking{Chars: 2, Mode: MaskPartial}.validate())
+	assert.ErrorIs(t, Masking{Mode: "stars"}.validate(), ErrInvalidMaskMode)
+	assert.ErrorIs(t, Masking{Chars: -1, Mode: MaskPartial}.validate(), ErrInvalidMaskMode)
+}
+
+func TestMaskKeyValues(t *testing.T) {
+	data := []KeyValue{
+		newKeyValue("keystore", string([]byte{0x00, 0xff, 0xfe})),
+		{Key: "password", Value: "hunter2"},
+		{Key: "username", Value: "admin"},
+	}
+
+	t.Run("length counts bytes of binary values", func(t *testing.T) {
+		t.Parallel()
+		got := maskKeyValues(data, Masking{Mode: MaskLength}, nil)
+		assert.Equal(t, []KeyValue{
+			{Key: "keystore", Value: "[3 bytes]"},
+			{Key: "password", Value: "[7 characters]"},
+			{Key: "username", Value: "[5 characters]"},
+		}, got)
+	})
+
+	t.Run("revealed keys stay in plaintext", func(t *testing.T) {
+		t.Parallel()
+		got := maskKeyValues(data, Masking{Chars: 2, Mode: MaskPartial}, []string{"username"})
+		assert.Equal(t, []KeyValue{
+			{Key: "keystore", Value: maskedValue},
+			{Key: "password", Value: "hu" + maskedValue + "r2"},
+			{Key: "username", Value: "admin"},
+		}, got)
+		assert.Equal(t, "hunter2", data[1].Value)
+	})
+}
+
+func TestProcessSecretMask(t *testing.T) {
+	secret := Secret{
+		Data: SecretData{
+			"password": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+			"username": base64.StdEncoding.EncodeToString([]byte("admin")),
+		},
+		Metadata: Metadata{Name: "creds", Namespace: "default"},
+		Type:     Opaque,
+	}
+
+	tests := map[string]struct {
+		opts    ProcessOptions
+		want    string
+		wantErr error
+	}{
+		"text": {
+			opts: ProcessOptions{DecodeAll: true, Mask: Masking{Mode: MaskFull}, OutputFormat: "text"},
+			want: "password='********'\nusername='********'\n",
+		},
+		"json": {
+			opts: ProcessOptions{Mask: Masking{Mode: MaskLength}, OutputFormat: "json", SecretKey: "password"},
+			want: `{
+  "data": [
+    {
+      "key": "password",
+      "value": "[7 characters]"
+    }
+  ],
+  "name": "creds",
+  "namespace": "default",
+  "type": "Opaque"
+}
+`,
+		},
+		"yaml": {
+			opts: ProcessOptions{DecodeAll: true, Mask: Masking{Chars: 3, Mode: MaskPartial}, OutputFormat: "yaml"},
+			want: `data:
+    - key: password
+      value: hun********er2
+    - key: username
+      value: '********'
+name: creds
+namespace: default
+type: Opaque
+`,
+		},
+		"invalid mode": {
+			opts:    ProcessOptions{DecodeAll: true, Mask: Masking{Mode: "stars"}, OutputFormat: "text"},
+			wantErr: ErrInvalidMaskMode,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var outBuf bytes.Buffer
+			err := ProcessSecretWithOptions(&outBuf, &bytes.Buffer{}, nil, secret, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+}
+
+func TestMaskFlag(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+stringData:
+  password: hunter2-long
+  username: admin
+`
+
+	tests := map[string]struct {
+		args    []string
+		want    string
+		wantErr error
+	}{
+		"without mode":           {args: []string{"-a", "--mask"}, want: "password='********'\nusername='********'\n"},
+		"full":                   {args: []string{"-a", "--mask=full"}, want: "password='********'\nusername='********'\n"},
+		"partial":                {args: []string{"-a", "--mask=partial", "--mask-chars", "2"}, want: "password='hu********ng'\nusername='ad********in'\n"},
+		"length":                 {args: []string{"password", "--mask=length"}, want: "[12 characters]\n"},
+		"without mode after key": {args: []string{"password", "--mask"}, want: "********\n"},
+		// the mode isn't taken from the next word, it's a key instead
+		"separate mode": {args: []string{"--mask", "partial"}, wantErr: ErrSecretKeyNotFound},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewCmdViewSecret()
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(manifest))
+			cmd.SetArgs(append([]string{"creds", "-f", "-"}, tt.args...))
+
+			err := cmd.Execute()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidQuery is thrown if a query can't be parsed
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrQueryNoMatch is thrown if a query doesn't match anything in a value
+	ErrQueryNoMatch = errors.New("query matched nothing")
+)
+
+// queryStep is a single field or index access of a query
+type queryStep struct {
+	field   string
+	index   int
+	isIndex bool
+}
+
+// valueQuery is a parsed jq-style path like `.auths["ghcr.io"].username` or `.items[0]`
+type valueQuery struct {
+	expr  string
+	steps []queryStep
+}
+
+// parseQuery parses a jq-style path expression
+//
+// Supported are the identity `.`, fields `.name`, quoted fields `."name"` or
+// `["name"]` and array indexes `[0]`, negative indexes count from the end.
+func parseQuery(expr string) (valueQuery, error) {
+	q := valueQuery{expr: expr}
+	rest := strings.TrimSpace(expr)
+
+	if !strings.HasPrefix(rest, ".") && !strings.HasPrefix(rest, "[") {
+		return q, fmt.Errorf("%w %q: must start with . or [", ErrInvalidQuery, expr)
+	}
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return q, fmt.Errorf("%w %q: missing ]", ErrInvalidQuery, expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			if strings.HasPrefix(inner, `"`) {
+				field, n, err := parseQuotedField(rest[1:])
+				if err != nil {
+					return q, fmt.Errorf("%w %q: %w", ErrInvalidQuery, expr, err)
+				}
+				after := strings.TrimSpace(rest[1+n:])
+				if !strings.HasPrefix(after, "]") {
+					return q, fmt.Errorf("%w %q: missing ]", ErrInvalidQuery, expr)
+				}
+				q.steps = append(q.steps, queryStep{field: field})
+				rest = after[1:]
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return q, fmt.Errorf("%w %q: index %q is not a number or quoted string", ErrInvalidQuery, expr, inner)
+			}
+			q.steps = append(q.steps, queryStep{index: idx, isIndex: true})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, `."`):
+			field, n, err := parseQuotedField(rest[1:])
+			if err != nil {
+				return q, fmt.Errorf("%w %q: %w", ErrInvalidQuery, expr, err)
+			}
+			q.steps = append(q.steps, queryStep{field: field})
+			rest = rest[1+n:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			n := strings.IndexAny(rest, ".[")
+			if n < 0 {
+				n = len(rest)
+			}
+			if n > 0 {
+				q.steps = append(q.steps, queryStep{field: rest[:n]})
+			} else if rest != "" && !strings.HasPrefix(rest, "[") {
+				return q, fmt.Errorf("%w %q: empty field name", ErrInvalidQuery, expr)
+			}
+			rest = rest[n:]
+		default:
+			return q, fmt.Errorf("%w %q: unexpected %q", ErrInvalidQuery, expr, rest)
+		}
+	}
+
+	return q, nil
+}
+
+// parseQuotedField parses a JSON quoted string at the start of s and returns it with the number of bytes consumed
+func parseQuotedField(s string) (string, int, error) {
+	escaped := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			var field string
+			if err := json.Unmarshal([]byte(s[:i+1]), &field); err != nil {
+				return "", 0, err
+			}
+			return field, i + 1, nil
+		}
+	}
+	return "", 0, errors.New("unterminated string")
+}
+
+// apply evaluates the query against a decoded JSON or YAML document
+func (q valueQuery) apply(value string) (any, error) {
+	var doc any
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, fmt.Errorf("%w: value is not a JSON or YAML document", ErrQueryNoMatch)
+	}
+
+	for _, step := range q.steps {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[step.field]
+			if step.isIndex || !ok {
+				return nil, ErrQueryNoMatch
+			}
+			doc = v
+		case []any:
+			idx := step.index
+			if idx < 0 {
+				idx += len(node)
+			}
+			if !step.isIndex || idx < 0 || idx >= len(node) {
+				return nil, ErrQueryNoMatch
+			}
+			doc = node[idx]
+		default:
+			return nil, ErrQueryNoMatch
+		}
+	}
+
+	return doc, nil
+}
+
+// formatQueryResult renders the result of a query as a value
+//
+// Scalars are returned as is, documents are rendered as YAML for the yaml
+// output format and as indented JSON otherwise.
+func formatQueryResult(result any, outputFormat string) (string, error) {
+	switch v := result.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "null", nil
+	case map[string]any, []any:
+		if outputFormat == "yaml" {
+			out, err := yaml.Marshal(v)
+			return string(out), err
+		}
+		out, err := json.MarshalIndent(v, "", "  ")
+		return string(out), err
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := map[string]struct {
+		expr    string
+		want    []queryStep
+		wantErr bool
+	}{
+		"identity":      {expr: ".", want: nil},
+		"fields":        {expr: ".a.b", want: []queryStep{{field: "a"}, {field: "b"}}},
+		"bracket field": {expr: `.auths["ghcr.io"].username`, want: []queryStep{{field: "auths"}, {field: "ghcr.io"}, {field: "username"}}},
+		"quoted field":  {expr: `."my.key"`, want: []queryStep{{field: "my.key"}}},
+		"escaped quote": {expr: `["a\"]b"]`, want: []queryStep{{field: `a"]b`}}},
+		"index":         {expr: ".items[0].name", want: []queryStep{{field: "items"}, {index: 0, isIndex: true}, {field: "name"}}},
+		"negative":      {expr: ".items[-1]", want: []queryStep{{field: "items"}, {index: -1, isIndex: true}}},
+		"no prefix":     {expr: "auths", wantErr: true},
+		"unterminated":  {expr: `.auths["ghcr.io`, wantErr: true},
+		"missing ]":     {expr: ".items[0", wantErr: true},
+		"bad index":     {expr: ".items[x]", wantErr: true},
+		"empty field":   {expr: "..a", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseQuery(tt.expr)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidQuery)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.steps)
+		})
+	}
+}
+
+func TestProcessSecretQuery(t *testing.T) {
+	dockerConfig := `{"auths":{"ghcr.io":{"username":"robot","password":"hunter2"},"quay.io":{"username":"quay"}}}`
+	appConfig := "database:\n  hosts:\n    - db-0\n    - db-1\n  port: 5432\n"
+	secret := Secret{
+		Data: SecretData{
+			"config.json": base64.StdEncoding.EncodeToString([]byte(dockerConfig)),
+			"app.yaml":    base64.StdEncoding.EncodeToString([]byte(appConfig)),
+			"password":    base64.StdEncoding.EncodeToString([]byte("hunter2")),
+		},
+		Metadata: Metadata{Name: "app", Namespace: "default"},
+		Type:     Opaque,
+	}
+
+	tests := map[string]struct {
+		opts    ProcessOptions
+		want    string
+		wantErr error
+	}{
+		"single key string": {
+			opts: ProcessOptions{OutputFormat: "text", Query: `.auths["ghcr.io"].username`, SecretKey: "config.json"},
+			want: "robot\n",
+		},
+		"single key yaml document": {
+			opts: ProcessOptions{OutputFormat: "text", Query: ".database.port", SecretKey: "app.yaml"},
+			want: "5432\n",
+		},
+		"object as json": {
+			opts: ProcessOptions{OutputFormat: "text", Query: `.auths["quay.io"]`, SecretKey: "config.json"},
+			want: "{\n  \"username\": \"quay\"\n}\n",
+		},
+		"all keys skips non matching": {
+			opts: ProcessOptions{DecodeAll: true, OutputFormat: "text", Query: ".database.hosts[-1]"},
+			want: "db-1\n",
+		},
+		"all keys yaml": {
+			opts: ProcessOptions{DecodeAll: true, OutputFormat: "yaml", Query: ".database.hosts"},
+			want: "data:\n    - key: app.yaml\n      value: |\n        - db-0\n        - db-1\nname: app\nnamespace: default\ntype: Opaque\n",
+		},
+		"raw": {
+			opts: ProcessOptions{Query: `.auths["ghcr.io"].password`, Raw: true, SecretKey: "config.json"},
+			want: "hunter2",
+		},
+		"no match": {
+			opts:    ProcessOptions{OutputFormat: "text", Query: ".auths.gcr", SecretKey: "config.json"},
+			wantErr: ErrQueryNoMatch,
+		},
+		"not a document": {
+			opts:    ProcessOptions{OutputFormat: "text", Query: ".auths", SecretKey: "password"},
+			wantErr: ErrQueryNoMatch,
+		},
+		"no match in any key": {
+			opts:    ProcessOptions{DecodeAll: true, OutputFormat: "text", Query: ".missing"},
+			wantErr: ErrQueryNoMatch,
+		},
+		"invalid query": {
+			opts:    ProcessOptions{DecodeAll: true, OutputFormat: "text", Query: "auths"},
+			wantErr: ErrInvalidQuery,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var outBuf, errBuf bytes.Buffer
+			err := ProcessSecretWithOptions(&outBuf, &errBuf, nil, secret, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+}
@@ -0,0 +1,462 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	diffExample = `
+	# compare a secret between two contexts, e.g. to verify staging and production credentials differ
+	%[1]s view-secret diff <secret> -c staging --other-context production
+
+	# check that a replicated secret is in sync with its origin
+	%[1]s view-secret diff <secret> -n origin --other-namespace replica
+
+	# compare two secrets in the same namespace and show masked line diffs of the changed values
+	%[1]s view-secret diff <secret> <other-secret> --values
+
+	# show the line diffs of the changed values in plaintext
+	%[1]s view-secret diff <secret> <other-secret> --values --reveal
+
+	# check whether the live secret matches a local manifest before applying it
+	%[1]s view-secret diff <secret> -f secret.yaml [--values]
+`
+
+	secretDiffNoChanges = "No differences between %s and %s\n"
+	secretDiffSummary   = "Compared %s with %s: %d added, %d removed, %d changed, %d unchanged\n"
+)
+
+// KeyDiffStatus describes how a key differs between two secrets
+type KeyDiffStatus string
+
+const (
+	KeyDiffAdded     KeyDiffStatus = "added"
+	KeyDiffChanged   KeyDiffStatus = "changed"
+	KeyDiffRemoved   KeyDiffStatus = "removed"
+	KeyDiffUnchanged KeyDiffStatus = "unchanged"
+)
+
+const (
+	// secretRefLive marks the cluster side of a diff against manifests
+	secretRefLive = "live"
+
+	// secretRefManifest marks the manifest side of a diff against manifests
+	secretRefManifest = "manifest"
+)
+
+var (
+	// ErrDiffSameSecret is thrown if both sides of the diff refer to the same secret
+	ErrDiffSameSecret = errors.New("nothing to compare, specify a second secret or --other-namespace, --other-context or --other-kubeconfig")
+
+	// ErrDiffOtherWithManifest is thrown if the other secret is located in a cluster and in manifests at the same time
+	ErrDiffOtherWithManifest = errors.New("--other-namespace, --other-context and --other-kubeconfig can't be combined with -f/--filename")
+)
+
+// DiffOpts is the struct holding the properties of the diff subcommand
+type DiffOpts struct {
+	sourceFlags
+
+	otherContext    string
+	otherKubeConfig string
+	otherNamespace  string
+	otherSecretName string
+	otherSource     SecretSource
+	outputFormat    string
+	reveal          bool
+	secretName      string
+	source          SecretSource
+	values          bool
+}
+
+// SecretRef identifies one side of a secret diff
+//
+// Source tells the live secret and the manifest apart when diffing against manifests.
+type SecretRef struct {
+	Context   string `json:"context,omitempty" yaml:"context,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Source    string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// String returns the reference as [source/][context:]namespace/name
+func (r SecretRef) String() string {
+	ref := r.Name
+	if r.Namespace != "" {
+		ref = r.Namespace + "/" + ref
+	}
+	if r.Context != "" {
+		ref = r.Context + ":" + ref
+	}
+	if r.Source != "" {
+		ref = r.Source + "/" + ref
+	}
+	return ref
+}
+
+// KeyDiff is the comparison result of a single key
+//
+// Diff holds the line diff of a changed value if requested, the lines are
+// masked unless the values are revealed.
+type KeyDiff struct {
+	Diff   string        `json:"diff,omitempty" yaml:"diff,omitempty"`
+	Key    string        `json:"key" yaml:"key"`
+	Status KeyDiffStatus `json:"status" yaml:"status"`
+}
+
+// SecretDiffSummary holds the number of keys per status
+type SecretDiffSummary struct {
+	Added     int `json:"added" yaml:"added"`
+	Changed   int `json:"changed" yaml:"changed"`
+	Removed   int `json:"removed" yaml:"removed"`
+	Unchanged int `json:"unchanged" yaml:"unchanged"`
+}
+
+// SecretDiffReport is the key by key comparison of two secrets
+type SecretDiffReport struct {
+	From    SecretRef         `json:"from" yaml:"from"`
+	Keys    []KeyDiff         `json:"keys" yaml:"keys"`
+	Summary SecretDiffSummary `json:"summary" yaml:"summary"`
+	To      SecretRef         `json:"to" yaml:"to"`
+}
+
+// secretDiffOptions controls whether and how line diffs of changed values are rendered
+type secretDiffOptions struct {
+	reveal bool
+	values bool
+}
+
+// newCmdDiff creates the cobra command comparing two secrets key by key
+func newCmdDiff() *cobra.Command {
+	res := &DiffOpts{}
+
+	cmd := &cobra.Command{
+		Args:         cobra.RangeArgs(1, 2),
+		Example:      fmt.Sprintf(diffExample, "kubectl"),
+		Short:        "Compare two secrets key by key, across namespaces, contexts, kubeconfigs or against manifests",
+		SilenceUsage: true,
+		Use:          "diff <secret> [other-secret]",
+		RunE: func(c *cobra.Command, args []string) error {
+			res.secretName = args[0]
+			res.otherSecretName = args[0]
+			if len(args) == 2 {
+				res.otherSecretName = args[1]
+			}
+			return res.Diff(c)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 1 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return getSecrets(cmd, args, toComplete)
+		},
+	}
+
+	res.sourceFlags.addFlags(cmd)
+	cmd.Flags().StringVar(&res.otherNamespace, "other-namespace", res.otherNamespace, "namespace of the other secret, defaults to --namespace")
+	cmd.Flags().StringVar(&res.otherContext, "other-context", res.otherContext, "context of the other secret, defaults to --context")
+	cmd.Flags().StringVar(&res.otherKubeConfig, "other-kubeconfig", res.otherKubeConfig, "kubeconfig of the other secret, defaults to --kubeconfig")
+	cmd.Flags().BoolVar(&res.values, "values", res.values, "if true, shows a line diff of each changed value with the lines masked")
+	cmd.Flags().BoolVar(&res.reveal, "reveal", res.reveal, "if true, shows the lines of the value diffs in plaintext, implies --values")
+	cmd.Flags().StringVarP(&res.outputFormat, "output", "o", "text", "output format: text, json, yaml")
+
+	_ = cmd.RegisterFlagCompletionFunc("other-namespace", getNamespaces)
+
+	return cmd
+}
+
+// Diff fetches both secrets and prints the comparison
+//
+// With manifests, the live secret read using the connection flags is compared
+// against the secret in the manifests, just like kubectl diff compares the live
+// object against the one which would be applied. Failures are returned as an
+// ExitError with code 2 since code 1 reports that the secrets differ.
+func (o *DiffOpts) Diff(cmd *cobra.Command) error {
+	err := o.diff(cmd)
+
+	// Like kubectl diff, exit code 1 reports differences and higher ones a failure
+	var exitErr *ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return &ExitError{Code: 2, Err: err}
+	}
+
+	return err
+}
+
+// diff reads both secrets and outputs the report
+func (o *DiffOpts) diff(cmd *cobra.Command) error {
+	fromOpts := sourceOptionsFromFlags(cmd)
+	toOpts := o.otherSourceOptions(fromOpts)
+
+	var (
+		fromSource, toSource SecretSource
+		err                  error
+	)
+	if len(o.filenames) > 0 {
+		if toOpts != fromOpts {
+			return ErrDiffOtherWithManifest
+		}
+		fromSource, toSource, err = o.manifestSources(cmd, fromOpts)
+	} else {
+		if fromOpts == toOpts && o.secretName == o.otherSecretName {
+			return ErrDiffSameSecret
+		}
+		fromSource, toSource, err = o.secretSources(cmd, fromOpts, toOpts)
+	}
+	if err != nil {
+		return err
+	}
+
+	from, err := fromSource.GetSecret(contextFromCommand(cmd), o.secretName)
+	if err != nil {
+		return err
+	}
+
+	to, err := toSource.GetSecret(contextFromCommand(cmd), o.otherSecretName)
+	if err != nil {
+		return err
+	}
+
+	fromRef, toRef := secretRef(from, fromOpts), secretRef(to, toOpts)
+	if len(o.filenames) > 0 {
+		fromRef.Source, toRef.Source = secretRefLive, secretRefManifest
+		toRef.Context = ""
+	}
+
+	report, err := diffSecrets(from, to, fromRef, toRef, secretDiffOptions{reveal: o.reveal, values: o.values || o.reveal})
+	if err != nil {
+		return err
+	}
+
+	return ProcessSecretDiff(cmd.OutOrStdout(), cmd.ErrOrStderr(), report, o.outputFormat)
+}
+
+// otherSourceOptions returns the connection settings of the other secret
+//
+// Anything not overridden is shared with the first secret.
+func (o *DiffOpts) otherSourceOptions(opts SourceOptions) SourceOptions {
+	if o.otherContext != "" {
+		opts.Context = o.otherContext
+	}
+	if o.otherKubeConfig != "" {
+		opts.KubeConfig = o.otherKubeConfig
+	}
+	if o.otherNamespace != "" {
+		opts.Namespace = o.otherNamespace
+	}
+	return opts
+}
+
+// secretSources returns the configured sources or creates them for both sets of connection settings
+func (o *DiffOpts) secretSources(cmd *cobra.Command, fromOpts, toOpts SourceOptions) (SecretSource, SecretSource, error) {
+	fromSource, toSource := o.source, o.otherSource
+	backend, _ := cmd.Flags().GetString("backend")
+
+	var err error
+	if fromSource == nil {
+		if fromSource, err = NewSecretSource(backend, fromOpts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if toSource == nil {
+		if toSource, err = NewSecretSource(backend, toOpts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return fromSource, toSource, nil
+}
+
+// manifestSources returns the source of the live secret and of the manifests
+//
+// The namespace selects the live secret and narrows down the manifests just
+// like it does when viewing a secret from manifests.
+func (o *DiffOpts) manifestSources(cmd *cobra.Command, opts SourceOptions) (SecretSource, SecretSource, error) {
+	liveSource, manifestSource := o.source, o.otherSource
+
+	var err error
+	if liveSource == nil {
+		backend, _ := cmd.Flags().GetString("backend")
+		if liveSource, err = NewSecretSource(backend, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if manifestSource == nil {
+		if manifestSource, err = newManifestSource(o.filenames, cmd.InOrStdin(), opts.Namespace); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return liveSource, manifestSource, nil
+}
+
+// secretRef identifies a secret, falling back to the requested namespace if the secret doesn't carry one
+func secretRef(secret Secret, opts SourceOptions) SecretRef {
+	ref := SecretRef{
+		Context:   opts.Context,
+		Name:      secret.Metadata.Name,
+		Namespace: secret.Metadata.Namespace,
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = opts.Namespace
+	}
+	return ref
+}
+
+// ProcessSecretDiff outputs the diff report and a summary on the error writer
+//
+// If the secrets differ an ExitError with code 1 is returned, just like kubectl diff.
+func ProcessSecretDiff(outWriter, errWriter io.Writer, report SecretDiffReport, outputFormat string) error {
+	var err error
+	switch outputFormat {
+	case "json":
+		err = writeJSON(outWriter, report)
+	case "yaml":
+		err = writeYAML(outWriter, report)
+	default:
+		err = report.renderText(outWriter)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	s := report.Summary
+	if s.Added+s.Removed+s.Changed == 0 {
+		_, err = fmt.Fprintf(errWriter, secretDiffNoChanges, report.From, report.To)
+	} else {
+		_, err = fmt.Fprintf(errWriter, secretDiffSummary, report.From, report.To, s.Added, s.Removed, s.Changed, s.Unchanged)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write to stderr: %w", err)
+	}
+
+	if s.Added+s.Removed+s.Changed > 0 {
+		return &ExitError{Code: 1, Message: "secrets differ"}
+	}
+
+	return nil
+}
+
+// renderText outputs the status of each key as a table followed by the value diffs
+func (r SecretDiffReport) renderText(outWriter io.Writer) error {
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KEY\tSTATUS")
+	for _, k := range r.Keys {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", k.Key, k.Status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, k := range r.Keys {
+		if k.Diff == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(outWriter, "\n%s", k.Diff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffSecrets compares the decoded values of two secrets key by key
+//
+// Keys only present in the second secret are added, keys only present in
+// the first one are removed.
+func diffSecrets(from, to Secret, fromRef, toRef SecretRef, opts secretDiffOptions) (SecretDiffReport, error) {
+	report := SecretDiffReport{
+		From: fromRef,
+		Keys: []KeyDiff{},
+		To:   toRef,
+	}
+
+	keySet := map[string]bool{}
+	for k := range from.Data {
+		keySet[k] = true
+	}
+	for k := range to.Data {
+		keySet[k] = true
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fromValue, inFrom := from.Data[key]
+		toValue, inTo := to.Data[key]
+
+		switch {
+		case !inFrom:
+			report.Keys = append(report.Keys, KeyDiff{Key: key, Status: KeyDiffAdded})
+			report.Summary.Added++
+			continue
+		case !inTo:
+			report.Keys = append(report.Keys, KeyDiff{Key: key, Status: KeyDiffRemoved})
+			report.Summary.Removed++
+			continue
+		}
+
+		fromDecoded, err := from.Decode(fromValue)
+		if err != nil {
+			return report, fmt.Errorf("failed to decode key %s of %s: %w", key, fromRef, err)
+		}
+		toDecoded, err := to.Decode(toValue)
+		if err != nil {
+			return report, fmt.Errorf("failed to decode key %s of %s: %w", key, toRef, err)
+		}
+
+		if fromDecoded == toDecoded {
+			report.Keys = append(report.Keys, KeyDiff{Key: key, Status: KeyDiffUnchanged})
+			report.Summary.Unchanged++
+			continue
+		}
+
+		kd := KeyDiff{Key: key, Status: KeyDiffChanged}
+		if opts.values {
+			kd.Diff = valueDiff(fromRef.String()+"/"+key, toRef.String()+"/"+key, fromDecoded, toDecoded, opts.reveal)
+		}
+		report.Keys = append(report.Keys, kd)
+		report.Summary.Changed++
+	}
+
+	return report, nil
+}
+
+// valueDiff returns the unified diff of two differing values
+//
+// Binary values aren't diffed. Unless revealed, the content of every line is
+// masked so that only the shape of the change is visible.
+func valueDiff(fromName, toName, from, to string, reveal bool) string {
+	if isBinary(from) || isBinary(to) {
+		return fmt.Sprintf("Binary values %s and %s differ\n", fromName, toName)
+	}
+
+	diff := unifiedDiff(fromName, toName, from, to)
+	if reveal {
+		return diff
+	}
+
+	lines := strings.SplitAfter(diff, "\n")
+	masking := Masking{Mode: MaskFull}
+	// the first two lines are the file headers, hunk headers start with @ and markers with \
+	for i := 2; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" || line[0] == '@' || line[0] == '\\' {
+			continue
+		}
+		lines[i] = line[:1] + masking.apply(strings.TrimSuffix(line[1:], "\n")) + "\n"
+	}
+
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSecrets(t *testing.T) {
+	from := newTestSecret(Opaque, "staging", "db", map[string]string{
+		"config":   "host: db\nport: 5432\nuser: app\n",
+		"keystore": string([]byte{0x00, 0x01}),
+		"old":      "gone",
+		"password": "same",
+	})
+	to := newTestSecret(Opaque, "production", "db", map[string]string{
+		"config":   "host: db\nport: 5433\nuser: app\n",
+		"keystore": string([]byte{0x00, 0x02}),
+		"new":      "fresh",
+		"password": "same",
+	})
+	fromRef := SecretRef{Name: "db", Namespace: "staging"}
+	toRef := SecretRef{Context: "prod", Name: "db", Namespace: "production"}
+
+	tests := map[string]struct {
+		opts     secretDiffOptions
+		wantDiff map[string]string
+	}{
+		"statuses only": {},
+		"masked values": {
+			opts: secretDiffOptions{values: true},
+			wantDiff: map[string]string{
+				"config": `--- staging/db/config
++++ prod:production/db/config
+@@ -1,3 +1,3 @@
+ ********
+-********
++********
+ ********
+`,
+				"keystore": "Binary values staging/db/keystore and prod:production/db/keystore differ\n",
+			},
+		},
+		"revealed values": {
+			opts: secretDiffOptions{reveal: true, values: true},
+			wantDiff: map[string]string{
+				"config": `--- staging/db/config
++++ prod:production/db/config
+@@ -1,3 +1,3 @@
+ host: db
+-port: 5432
++port: 5433
+ user: app
+`,
+				"keystore": "Binary values staging/db/keystore and prod:production/db/keystore differ\n",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := diffSecrets(from, to, fromRef, toRef, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, []KeyDiff{
+				{Diff: tt.wantDiff["config"], Key: "config", Status: KeyDiffChanged},
+				{Diff: tt.wantDiff["keystore"], Key: "keystore", Status: KeyDiffChanged},
+				{Key: "new", Status: KeyDiffAdded},
+				{Key: "old", Status: KeyDiffRemoved},
+				{Key: "password", Status: KeyDiffUnchanged},
+			}, report.Keys)
+			assert.Equal(t, SecretDiffSummary{Added: 1, Changed: 2, Removed: 1, Unchanged: 1}, report.Summary)
+		})
+	}
+}
+
+func TestSecretDiffCommand(t *testing.T) {
+	staging := &fakeSource{secrets: []Secret{
+		newTestSecret(Opaque, "staging", "db", map[string]string{"password": "s3cret", "user": "app"}),
+		newTestSecret(Opaque, "staging", "db-copy", map[string]string{"password": "s3cret", "user": "app"}),
+	}}
+	production := &fakeSource{secrets: []Secret{
+		newTestSecret(Opaque, "production", "db", map[string]string{"password": "pr0d", "user": "app"}),
+	}}
+
+	tests := map[string]struct {
+		opts       DiffOpts
+		wantCode   int
+		wantStdOut string
+		wantStdErr string
+		wantErr    error
+	}{
+		"text": {
+			opts:     DiffOpts{otherNamespace: "production", otherSecretName: "db", otherSource: production, secretName: "db", source: staging},
+			wantCode: 1,
+			wantStdOut: `KEY       STATUS
+password  changed
+user      unchanged
+`,
+			wantStdErr: "Compared staging/db with production/db: 0 added, 0 removed, 1 changed, 1 unchanged\n",
+		},
+		"json": {
+			opts:     DiffOpts{otherNamespace: "production", otherSecretName: "db", otherSource: production, outputFormat: "json", secretName: "db", source: staging},
+			wantCode: 1,
+			wantStdOut: `{
+  "from": {
+    "name": "db",
+    "namespace": "staging"
+  },
+  "keys": [
+    {
+      "key": "password",
+      "status": "changed"
+    },
+    {
+      "key": "user",
+      "status": "unchanged"
+    }
+  ],
+  "summary": {
+    "added": 0,
+    "changed": 1,
+    "removed": 0,
+    "unchanged": 1
+  },
+  "to": {
+    "name": "db",
+    "namespace": "production"
+  }
+}
+`,
+			wantStdErr: "Compared staging/db with production/db: 0 added, 0 removed, 1 changed, 1 unchanged\n",
+		},
+		"in sync": {
+			opts: DiffOpts{otherSecretName: "db-copy", otherSource: staging, secretName: "db", source: staging},
+			wantStdOut: `KEY       STATUS
+password  unchanged
+user      unchanged
+`,
+			wantStdErr: "No differences between staging/db and staging/db-copy\n",
+		},
+		"same secret": {
+			opts:     DiffOpts{otherSecretName: "db", otherSource: staging, secretName: "db", source: staging},
+			wantCode: 2,
+			wantErr:  ErrDiffSameSecret,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			stdOutBuf := bytes.Buffer{}
+			stdErrBuf := bytes.Buffer{}
+			cmd.SetOut(&stdOutBuf)
+			cmd.SetErr(&stdErrBuf)
+
+			err := tt.opts.Diff(cmd)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.wantCode != 0 {
+				var exitErr *ExitError
+				if assert.ErrorAs(t, err, &exitErr) {
+					assert.Equal(t, tt.wantCode, exitErr.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, tt.wantStdOut, stdOutBuf.String())
+			assert.Equal(t, tt.wantStdErr, stdErrBuf.String())
+		})
+	}
+}
+
+func TestSecretDiffManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "secret.yaml")
+	assert.NoError(t, os.WriteFile(manifest, []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+data:
+  user: YXBw
+stringData:
+  password: n3w
+  port: "5432"
+`), 0o600))
+
+	live := &fakeSource{secrets: []Secret{
+		newTestSecret(Opaque, "default", "db", map[string]string{"password": "old", "tls.key": "key", "user": "app"}),
+	}}
+
+	tests := map[string]struct {
+		opts       DiffOpts
+		wantCode   int
+		wantStdOut string
+		wantStdErr string
+		wantErr    error
+	}{
+		"drift": {
+			opts:     DiffOpts{otherSecretName: "db", reveal: true, secretName: "db", source: live, sourceFlags: sourceFlags{filenames: []string{manifest}}},
+			wantCode: 1,
+			wantStdOut: `KEY       STATUS
+password  changed
+port      added
+tls.key   removed
+user      unchanged
+
+--- live/default/db/password
++++ manifest/db/password
+@@ -1 +1 @@
+-old
+\ No newline at end of file
++n3w
+\ No newline at end of file
+`,
+			wantStdErr: "Compared live/default/db with manifest/db: 1 added, 1 removed, 1 changed, 1 unchanged\n",
+		},
+		"missing in manifest": {
+			opts:     DiffOpts{otherSecretName: "other", secretName: "db", source: live, sourceFlags: sourceFlags{filenames: []string{manifest}}},
+			wantCode: 2,
+			wantErr:  ErrManifestSecretNotFound,
+		},
+		"other flags": {
+			opts:     DiffOpts{otherContext: "prod", otherSecretName: "db", secretName: "db", source: live, sourceFlags: sourceFlags{filenames: []string{manifest}}},
+			wantCode: 2,
+			wantErr:  ErrDiffOtherWithManifest,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			stdOutBuf := bytes.Buffer{}
+			stdErrBuf := bytes.Buffer{}
+			cmd.SetOut(&stdOutBuf)
+			cmd.SetErr(&stdErrBuf)
+
+			err := tt.opts.Diff(cmd)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.wantCode != 0 {
+				var exitErr *ExitError
+				if assert.ErrorAs(t, err, &exitErr) {
+					assert.Equal(t, tt.wantCode, exitErr.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, tt.wantStdOut, stdOutBuf.String())
+			assert.Equal(t, tt.wantStdErr, stdErrBuf.String())
+		})
+	}
+}
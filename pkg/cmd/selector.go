@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ErrSelectorWithSecretName is thrown if a secret name is combined with flags selecting multiple secrets
+	ErrSelectorWithSecretName = errors.New("-l/--selector, --field-selector and -A/--all-namespaces select the secrets, don't specify a secret name")
+
+	// ErrMultipleSecretsUnsupported is thrown if an option only works for a single secret
+	ErrMultipleSecretsUnsupported = errors.New("not supported when selecting multiple secrets")
+)
+
+// secretValues holds the decoded values of one of multiple selected secrets
+type secretValues struct {
+	data   []KeyValue
+	secret Secret
+}
+
+// selectsMultiple reports whether the flags select multiple secrets instead of a single one
+func (c *CommandOpts) selectsMultiple() bool {
+	return c.allNamespaces || c.labelSelector != "" || c.fieldSelector != ""
+}
+
+// retrieveMultiple lists the secrets matching the selectors and decodes all of them
+func (c *CommandOpts) retrieveMultiple(cmd *cobra.Command, source SecretSource) error {
+	if c.secretName != "" {
+		return ErrSelectorWithSecretName
+	}
+
+	if err := c.validateMultiple(); err != nil {
+		return err
+	}
+
+	opts := c.processOptions()
+	if err := c.loadHMACKey(&opts); err != nil {
+		return err
+	}
+
+	secretList, err := source.ListSecrets(contextFromCommand(cmd), ListOptions{
+		AllNamespaces: c.allNamespaces,
+		FieldSelector: c.fieldSelector,
+		LabelSelector: c.labelSelector,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.quiet {
+		return ProcessSecretsWithOptions(cmd.OutOrStdout(), io.Discard, secretList.Items, opts)
+	}
+
+	return ProcessSecretsWithOptions(cmd.OutOrStdout(), cmd.OutOrStderr(), secretList.Items, opts)
+}
+
+// validateMultiple rejects the options which only work for a single secret
+func (c *CommandOpts) validateMultiple() error {
+	unsupported := map[string]bool{
+		"--clipboard":          c.clipboard,
+		"--details":            c.details || c.registry != "",
+		"--helm-part":          c.helmPart != "",
+		"--raw":                c.raw,
+		"--to-dir":             c.toDir != "",
+		"-o " + c.outputFormat: isEnvFormat(c.outputFormat),
+	}
+
+	flags := make([]string, 0, len(unsupported))
+	for flag, set := range unsupported {
+		if set {
+			flags = append(flags, flag)
+		}
+	}
+	sort.Strings(flags)
+
+	if len(flags) > 0 {
+		return fmt.Errorf("%s: %w", flags[0], ErrMultipleSecretsUnsupported)
+	}
+
+	return nil
+}
+
+// ProcessSecretsWithOptions decodes all keys of multiple secrets
+//
+// The secrets are sorted by namespace and name. Text output is grouped by
+// secret, json and yaml output is a list with one object per secret. With a
+// query or selected keys, secrets without a matching key are left out.
+func ProcessSecretsWithOptions(outWriter, errWriter io.Writer, secrets []Secret, opts ProcessOptions) error {
+	if len(secrets) == 0 {
+		return ErrNoSecretFound
+	}
+
+	if err := opts.Mask.validate(); err != nil {
+		return err
+	}
+
+	if err := validateHash(opts.Hash); err != nil {
+		return err
+	}
+
+	// digests don't reveal the values, masking them would hide the only output
+	if opts.Hash != "" {
+		opts.Mask = Masking{}
+	}
+
+	sorted := make([]Secret, len(secrets))
+	copy(sorted, secrets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Metadata.Namespace != sorted[j].Metadata.Namespace {
+			return sorted[i].Metadata.Namespace < sorted[j].Metadata.Namespace
+		}
+		return sorted[i].Metadata.Name < sorted[j].Metadata.Name
+	})
+
+	keys := opts.keySelection()
+
+	values := make([]secretValues, 0, len(sorted))
+	for _, secret := range sorted {
+		selected, _ := filterSecretData(secret.Data, keys, opts.ExcludeKeys)
+		if len(keys) > 0 && len(selected) == 0 {
+			continue
+		}
+
+		data := []KeyValue{}
+		if len(selected) > 0 {
+			decoded, err := decodeAllData(secret, selected, opts)
+			if opts.Query != "" && errors.Is(err, ErrQueryNoMatch) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s/%s: %w", secret.Metadata.Namespace, secret.Metadata.Name, err)
+			}
+			data = decoded
+		} else if opts.Query != "" {
+			continue
+		}
+		values = append(values, secretValues{data: data, secret: secret})
+	}
+
+	if len(values) == 0 && opts.Query != "" {
+		return fmt.Errorf("%w in any secret: %s", ErrQueryNoMatch, opts.Query)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("%w in any secret: %s", ErrSecretKeyNotFound, strings.Join(keys, ", "))
+	}
+
+	switch opts.OutputFormat {
+	case "json", "yaml":
+		return outputSecretList(outWriter, values, opts)
+	default:
+		return outputSecretGroups(outWriter, errWriter, values, opts)
+	}
+}
+
+// outputSecretList outputs the secrets as a list of the objects used for a single secret
+func outputSecretList(outWriter io.Writer, values []secretValues, opts ProcessOptions) error {
+	list := make([]map[string]any, 0, len(values))
+	for _, v := range values {
+		list = append(list, buildOutputMap(v.secret, maskKeyValues(v.data, opts.Mask, opts.RevealKeys)))
+	}
+
+	var err error
+	if opts.OutputFormat == "json" {
+		err = writeJSON(outWriter, list)
+	} else {
+		err = writeYAML(outWriter, list)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// outputSecretGroups outputs the values of each secret below a namespace/name header
+func outputSecretGroups(outWriter, errWriter io.Writer, values []secretValues, opts ProcessOptions) error {
+	for i, v := range values {
+		if err := reportLayers(errWriter, v.data); err != nil {
+			return err
+		}
+
+		header := fmt.Sprintf("# %s/%s\n", v.secret.Metadata.Namespace, v.secret.Metadata.Name)
+		if i > 0 {
+			header = "\n" + header
+		}
+		if _, err := fmt.Fprint(outWriter, header); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+
+		if err := writeText(outWriter, maskKeyValues(v.data, opts.Mask, opts.RevealKeys), true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
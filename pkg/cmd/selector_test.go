@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessSecretsWithOptions(t *testing.T) {
+	secrets := []Secret{
+		newTestSecret(Opaque, "payments", "tls", map[string]string{"tls.crt": "cert"}),
+		newTestSecret(Opaque, "payments", "db", map[string]string{"password": "hunter2", "user": "app"}),
+		{Metadata: Metadata{Name: "empty", Namespace: "checkout"}, Type: Opaque},
+		{
+			Data:     SecretData{"config": base64.StdEncoding.EncodeToString([]byte(`{"user":"checkout"}`))},
+			Metadata: Metadata{Name: "config", Namespace: "checkout"},
+			Type:     Opaque,
+		},
+	}
+
+	tests := map[string]struct {
+		opts    ProcessOptions
+		want    string
+		wantErr error
+	}{
+		"text grouped by secret": {
+			opts: ProcessOptions{OutputFormat: "text"},
+			want: `# checkout/config
+config='{"user":"checkout"}'
+
+# checkout/empty
+
+# payments/db
+password='hunter2'
+user='app'
+
+# payments/tls
+tls.crt='cert'
+`,
+		},
+		"json list": {
+			opts: ProcessOptions{Mask: Masking{Mode: MaskFull}, OutputFormat: "json", Query: ".user"},
+			want: `[
+  {
+    "data": [
+      {
+        "key": "config",
+        "value": "********"
+      }
+    ],
+    "name": "config",
+    "namespace": "checkout",
+    "type": "Opaque"
+  }
+]
+`,
+		},
+		"yaml list": {
+			opts: ProcessOptions{Hash: "sha256", OutputFormat: "yaml", Query: ".user"},
+			want: `- data:
+    - key: config
+      value: sha256:c7761e58969f7edd498186641b2021e8477e1bcd230de4cf3435242da4a40d14
+  name: config
+  namespace: checkout
+  type: Opaque
+`,
+		},
+		"query without match": {
+			opts:    ProcessOptions{OutputFormat: "text", Query: ".missing"},
+			wantErr: ErrQueryNoMatch,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var outBuf bytes.Buffer
+			err := ProcessSecretsWithOptions(&outBuf, &bytes.Buffer{}, secrets, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+
+	t.Run("no secrets", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorIs(t, ProcessSecretsWithOptions(&bytes.Buffer{}, &bytes.Buffer{}, nil, ProcessOptions{}), ErrNoSecretFound)
+	})
+}
+
+func TestRetrieveMultipleSelectedKeys(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+  labels:
+    app: x
+stringData:
+  password: hunter2
+  user: app
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: api
+  namespace: default
+  labels:
+    app: x
+stringData:
+  token: abc
+`
+
+	tests := map[string]struct {
+		args    []string
+		want    string
+		wantErr error
+	}{
+		"single key":    {args: []string{"--keys", "password"}, want: "# default/db\npassword='hunter2'\n"},
+		"multiple keys": {args: []string{"--keys", "password,token"}, want: "# default/api\ntoken='abc'\n\n# default/db\npassword='hunter2'\n"},
+		"missing key":   {args: []string{"--keys", "missing"}, wantErr: ErrSecretKeyNotFound},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewCmdViewSecret()
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(manifest))
+			cmd.SetArgs(append([]string{"-f", "-", "-l", "app=x"}, tt.args...))
+
+			err := cmd.Execute()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outBuf.String())
+		})
+	}
+}
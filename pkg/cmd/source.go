@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// BackendAPI talks to the Kubernetes API server directly using the kubeconfig
+	BackendAPI = "api"
+
+	// BackendKubectl shells out to the kubectl binary found in $PATH
+	BackendKubectl = "kubectl"
+)
+
+// ErrUnknownBackend is thrown when the requested secret source backend doesn't exist
+var ErrUnknownBackend = errors.New("unknown backend")
+
+// SecretSource is an interface for retrieving kubernetes secrets from a backend
+type SecretSource interface {
+	// GetSecret returns the secret with the given name
+	GetSecret(ctx context.Context, name string) (Secret, error)
+
+	// ListSecrets returns all secrets in the configured namespace or across all namespaces
+	ListSecrets(ctx context.Context, opts ListOptions) (SecretList, error)
+
+	// ListSecretNames returns the names of the secrets in the configured namespace without their data
+	ListSecretNames(ctx context.Context) ([]string, error)
+
+	// ListNamespaces returns the names of all namespaces
+	ListNamespaces(ctx context.Context) ([]string, error)
+
+	// ListKeys returns the sorted data keys of the secret with the given name
+	ListKeys(ctx context.Context, name string) ([]string, error)
+}
+
+// ListOptions holds the settings narrowing down which secrets are listed
+//
+// The selectors use the syntax of kubectl's -l/--selector and --field-selector.
+type ListOptions struct {
+	AllNamespaces bool
+	FieldSelector string
+	LabelSelector string
+}
+
+// SourceOptions holds the connection settings shared by all backends
+type SourceOptions struct {
+	Context           string
+	Impersonate       string
+	ImpersonateGroups string
+	KubeConfig        string
+	Namespace         string
+}
+
+// sourceFlags holds the flags selecting where secrets are read from
+//
+// It is shared by all commands so that every subcommand supports the same
+// namespace, context, kubeconfig, impersonation, backend and manifest overrides.
+type sourceFlags struct {
+	backend             string
+	customContext       string
+	customNamespace     string
+	filenames           []string
+	impersonateAs       string
+	impersonateAsGroups string
+	kubeConfig          string
+}
+
+// addFlags registers the source flags and their shell completions on the command
+func (f *sourceFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().
+		StringVarP(&f.customNamespace, "namespace", "n", f.customNamespace, "override the namespace defined in the current context")
+	cmd.Flags().StringVarP(&f.customContext, "context", "c", f.customContext, "override the current context")
+	cmd.Flags().StringVarP(&f.kubeConfig, "kubeconfig", "k", f.kubeConfig, "explicitly provide the kubeconfig to use")
+	cmd.Flags().StringVar(&f.impersonateAs, "as", f.impersonateAs, "Username to impersonate for the operation. User could be a regular user or a service account in a namespace.")
+	cmd.Flags().StringVar(&f.impersonateAsGroups, "as-group", f.impersonateAsGroups, "Groups to impersonate for the operation. Multipe groups can be specified by comma separated.")
+	cmd.Flags().StringVar(&f.backend, "backend", BackendKubectl, "backend used to retrieve secrets: kubectl, api")
+	cmd.Flags().StringSliceVarP(&f.filenames, "filename", "f", f.filenames, "read secrets from manifest files or directories instead of the cluster, use '-' for stdin")
+
+	_ = cmd.RegisterFlagCompletionFunc("namespace", getNamespaces)
+	_ = cmd.RegisterFlagCompletionFunc("backend", cobra.FixedCompletions([]string{BackendKubectl, BackendAPI}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.MarkFlagFilename("filename", "json", "yaml", "yml")
+}
+
+// NewSecretSource creates the secret source for the given backend
+func NewSecretSource(backend string, opts SourceOptions) (SecretSource, error) {
+	switch backend {
+	case "", BackendKubectl:
+		return newKubectlSource(opts), nil
+	case BackendAPI:
+		return newAPISource(opts)
+	default:
+		return nil, fmt.Errorf("%w %q, must be one of: %s, %s", ErrUnknownBackend, backend, BackendKubectl, BackendAPI)
+	}
+}
+
+// sourceOptionsFromFlags reads the connection overrides from the command flags
+func sourceOptionsFromFlags(cmd *cobra.Command) SourceOptions {
+	nsOverride, _ := cmd.Flags().GetString("namespace")
+	ctxOverride, _ := cmd.Flags().GetString("context")
+	kubeConfigOverride, _ := cmd.Flags().GetString("kubeconfig")
+	impersonateOverride, _ := cmd.Flags().GetString("as")
+	impersonateGroupOverride, _ := cmd.Flags().GetString("as-group")
+
+	return SourceOptions{
+		Context:           ctxOverride,
+		Impersonate:       impersonateOverride,
+		ImpersonateGroups: impersonateGroupOverride,
+		KubeConfig:        kubeConfigOverride,
+		Namespace:         nsOverride,
+	}
+}
+
+// newSecretSourceFromFlags creates the secret source selected by the command flags
+//
+// If manifest files were provided, secrets are read from those instead of a cluster.
+func newSecretSourceFromFlags(cmd *cobra.Command) (SecretSource, error) {
+	filenames, _ := cmd.Flags().GetStringSlice("filename")
+	if len(filenames) > 0 {
+		nsOverride, _ := cmd.Flags().GetString("namespace")
+		return newManifestSource(filenames, cmd.InOrStdin(), nsOverride)
+	}
+
+	backend, _ := cmd.Flags().GetString("backend")
+	return NewSecretSource(backend, sourceOptionsFromFlags(cmd))
+}
+
+// contextFromCommand returns the command context or a background context if none is set
+func contextFromCommand(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
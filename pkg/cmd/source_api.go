@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// apiSource retrieves secrets from the Kubernetes API server without requiring kubectl
+type apiSource struct {
+	client    kubernetes.Interface
+	metadata  metadata.Interface
+	namespace string
+}
+
+// newAPISource creates a secret source backed by the Kubernetes API using the kubeconfig loading rules of kubectl
+func newAPISource(opts SourceOptions) (*apiSource, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if opts.KubeConfig != "" {
+		rules.ExplicitPath = opts.KubeConfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: opts.Context,
+	}
+	overrides.Context.Namespace = opts.Namespace
+	overrides.AuthInfo.Impersonate = opts.Impersonate
+	if opts.ImpersonateGroups != "" {
+		overrides.AuthInfo.ImpersonateGroups = strings.Split(opts.ImpersonateGroups, ",")
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine namespace: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	metadataClient, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes metadata client: %w", err)
+	}
+
+	return &apiSource{client: client, metadata: metadataClient, namespace: namespace}, nil
+}
+
+// GetSecret returns the secret with the given name
+func (s *apiSource) GetSecret(ctx context.Context, name string) (Secret, error) {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return Secret{}, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	return secretFromAPI(secret), nil
+}
+
+// ListSecrets returns all secrets in the configured namespace or across all namespaces
+func (s *apiSource) ListSecrets(ctx context.Context, opts ListOptions) (SecretList, error) {
+	namespace := s.namespace
+	if opts.AllNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+
+	secrets, err := s.client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: opts.FieldSelector,
+		LabelSelector: opts.LabelSelector,
+	})
+	if err != nil {
+		return SecretList{}, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	secretList := SecretList{Items: make([]Secret, 0, len(secrets.Items))}
+	for i := range secrets.Items {
+		secretList.Items = append(secretList.Items, secretFromAPI(&secrets.Items[i]))
+	}
+
+	return secretList, nil
+}
+
+// ListSecretNames returns the names of the secrets in the configured namespace without their data
+//
+// Only the object metadata is requested, so the values never leave the API server.
+func (s *apiSource) ListSecretNames(ctx context.Context) ([]string, error) {
+	secrets, err := s.metadata.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).Namespace(s.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	names := make([]string, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		names = append(names, secret.Name)
+	}
+
+	return names, nil
+}
+
+// ListNamespaces returns the names of all namespaces
+func (s *apiSource) ListNamespaces(ctx context.Context) ([]string, error) {
+	namespaceList, err := s.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, ns := range namespaceList.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+
+	return namespaces, nil
+}
+
+// ListKeys returns the sorted data keys of the secret with the given name
+func (s *apiSource) ListKeys(ctx context.Context, name string) ([]string, error) {
+	secret, err := s.GetSecret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// secretFromAPI converts an API secret into the representation used by the decoders
+//
+// The API client returns the raw bytes, so the values are base64 encoded again
+// to match the payload kubectl prints with `-o json`.
+func secretFromAPI(s *corev1.Secret) Secret {
+	data := make(SecretData, len(s.Data))
+	for k, v := range s.Data {
+		data[k] = base64.StdEncoding.EncodeToString(v)
+	}
+
+	return Secret{
+		Data: data,
+		Metadata: Metadata{
+			Labels:    s.Labels,
+			Name:      s.Name,
+			Namespace: s.Namespace,
+		},
+		Type: SecretType(s.Type),
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+func newFakeAPISource(namespace string) *apiSource {
+	client := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "another"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Data:       map[string][]byte{"key1": []byte("value1"), "key2": []byte("value2")},
+			Type:       corev1.SecretTypeOpaque,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "gopher", Namespace: "another"},
+			Data:       map[string][]byte{"foo": []byte("bar")},
+			Type:       corev1.SecretTypeBasicAuth,
+		},
+	)
+
+	scheme := metadatafake.NewTestScheme()
+	_ = metav1.AddMetaToScheme(scheme)
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme,
+		newSecretMetadata("default", "test"),
+		newSecretMetadata("another", "gopher"),
+	)
+
+	return &apiSource{client: client, metadata: metadataClient, namespace: namespace}
+}
+
+// newSecretMetadata creates the metadata of a secret as returned by the metadata client
+func newSecretMetadata(namespace, name string) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+	}
+}
+
+func TestAPISource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get secret", func(t *testing.T) {
+		got, err := newFakeAPISource("default").GetSecret(ctx, "test")
+		assert.NoError(t, err)
+		assert.Equal(t, Secret{
+			Data:     SecretData{"key1": "dmFsdWUx", "key2": "dmFsdWUy"},
+			Metadata: Metadata{Name: "test", Namespace: "default"},
+			Type:     Opaque,
+		}, got)
+	})
+
+	t.Run("get secret in other namespace", func(t *testing.T) {
+		got, err := newFakeAPISource("another").GetSecret(ctx, "gopher")
+		assert.NoError(t, err)
+		assert.Equal(t, BasicAuth, got.Type)
+	})
+
+	t.Run("get missing secret", func(t *testing.T) {
+		_, err := newFakeAPISource("another").GetSecret(ctx, "test")
+		assert.ErrorContains(t, err, `secrets "test" not found`)
+	})
+
+	t.Run("list secrets", func(t *testing.T) {
+		got, err := newFakeAPISource("default").ListSecrets(ctx, ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, got.Items, 1)
+		assert.Equal(t, "test", got.Items[0].Metadata.Name)
+	})
+
+	t.Run("list secrets in all namespaces", func(t *testing.T) {
+		got, err := newFakeAPISource("default").ListSecrets(ctx, ListOptions{AllNamespaces: true})
+		assert.NoError(t, err)
+		assert.Len(t, got.Items, 2)
+	})
+
+	t.Run("list secrets by label", func(t *testing.T) {
+		source := &apiSource{client: fake.NewClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "payments", Labels: map[string]string{"app": "payments"}}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "checkout", Labels: map[string]string{"app": "checkout"}}},
+		), namespace: "default"}
+
+		got, err := source.ListSecrets(ctx, ListOptions{AllNamespaces: true, LabelSelector: "app=payments"})
+		assert.NoError(t, err)
+		assert.Equal(t, []Secret{{
+			Data:     SecretData{},
+			Metadata: Metadata{Labels: map[string]string{"app": "payments"}, Name: "db", Namespace: "payments"},
+		}}, got.Items)
+	})
+
+	t.Run("list secret names", func(t *testing.T) {
+		source := newFakeAPISource("default")
+		got, err := source.ListSecretNames(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test"}, got)
+
+		// the names are read from the metadata only, the values aren't fetched
+		assert.Empty(t, source.client.(*fake.Clientset).Actions())
+	})
+
+	t.Run("list namespaces", func(t *testing.T) {
+		got, err := newFakeAPISource("default").ListNamespaces(ctx)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"default", "another"}, got)
+	})
+
+	t.Run("list keys", func(t *testing.T) {
+		got, err := newFakeAPISource("default").ListKeys(ctx, "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"key1", "key2"}, got)
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		secret, err := newFakeAPISource("default").GetSecret(ctx, "test")
+		assert.NoError(t, err)
+		got, err := secret.Decode(secret.Data["key1"])
+		assert.NoError(t, err)
+		assert.Equal(t, "value1", got)
+	})
+}
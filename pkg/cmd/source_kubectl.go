@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+
+	"github.com/goccy/go-json"
+)
+
+// kubectlSource retrieves secrets by executing the kubectl binary
+type kubectlSource struct {
+	opts SourceOptions
+}
+
+// newKubectlSource creates a secret source backed by kubectl
+func newKubectlSource(opts SourceOptions) *kubectlSource {
+	return &kubectlSource{opts: opts}
+}
+
+// GetSecret returns the secret with the given name
+func (s *kubectlSource) GetSecret(ctx context.Context, name string) (Secret, error) {
+	var secret Secret
+
+	output, err := s.executeKubectlCommand(ctx, s.buildKubectlCommand("get", "secret", name, "-o", "json"))
+	if err != nil {
+		return secret, err
+	}
+
+	if err := json.Unmarshal(output, &secret); err != nil {
+		return secret, fmt.Errorf("failed to parse kubectl output as secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// ListSecrets returns all secrets in the configured namespace or across all namespaces
+func (s *kubectlSource) ListSecrets(ctx context.Context, opts ListOptions) (SecretList, error) {
+	var secretList SecretList
+
+	source := s
+	commandArgs := []string{"get", "secret", "-o", "json"}
+	if opts.AllNamespaces {
+		// kubectl ignores -n when -A is set but it's cleaner not to pass both
+		source = newKubectlSource(s.opts)
+		source.opts.Namespace = ""
+		commandArgs = append(commandArgs, "-A")
+	}
+	if opts.LabelSelector != "" {
+		commandArgs = append(commandArgs, "-l", opts.LabelSelector)
+	}
+	if opts.FieldSelector != "" {
+		commandArgs = append(commandArgs, "--field-selector", opts.FieldSelector)
+	}
+
+	output, err := s.executeKubectlCommand(ctx, source.buildKubectlCommand(commandArgs...))
+	if err != nil {
+		return secretList, err
+	}
+
+	if err := json.Unmarshal(output, &secretList); err != nil {
+		return secretList, fmt.Errorf("failed to parse kubectl output as secret list: %w", err)
+	}
+
+	return secretList, nil
+}
+
+// ListSecretNames returns the names of the secrets in the configured namespace without their data
+func (s *kubectlSource) ListSecretNames(ctx context.Context) ([]string, error) {
+	output, err := s.executeKubectlCommand(ctx, s.buildKubectlCommand("get", "secrets", "-o", "jsonpath={.items[*].metadata.name}"))
+	if err != nil {
+		return nil, err
+	}
+
+	return splitNames(output), nil
+}
+
+// ListNamespaces returns the names of all namespaces
+func (s *kubectlSource) ListNamespaces(ctx context.Context) ([]string, error) {
+	output, err := s.executeKubectlCommand(ctx, s.buildKubectlCommand("get", "namespaces", "-o", "jsonpath={.items[*].metadata.name}"))
+	if err != nil {
+		return nil, err
+	}
+
+	return splitNames(output), nil
+}
+
+// splitNames splits the space separated names printed by a kubectl jsonpath query
+func splitNames(output []byte) []string {
+	names := []string{}
+	for name := range bytes.SplitSeq(output, []byte(" ")) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+
+	return names
+}
+
+// ListKeys returns the sorted data keys of the secret with the given name
+func (s *kubectlSource) ListKeys(ctx context.Context, name string) ([]string, error) {
+	output, err := s.executeKubectlCommand(ctx, s.buildKubectlCommand("get", "secret", name, "-o", "jsonpath={.data}"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the JSON output to extract keys
+	var data map[string]string
+	if err := json.Unmarshal(output, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl output as secret data: %w", err)
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// buildKubectlCommand appends the connection overrides to the given kubectl arguments
+func (s *kubectlSource) buildKubectlCommand(args ...string) []string {
+	commandArgs := args
+
+	if s.opts.Namespace != "" {
+		commandArgs = append(commandArgs, "-n", s.opts.Namespace)
+	}
+
+	if s.opts.Context != "" {
+		commandArgs = append(commandArgs, "--context", s.opts.Context)
+	}
+
+	if s.opts.KubeConfig != "" {
+		commandArgs = append(commandArgs, "--kubeconfig", s.opts.KubeConfig)
+	}
+
+	if s.opts.Impersonate != "" {
+		commandArgs = append(commandArgs, "--as", s.opts.Impersonate)
+	}
+
+	if s.opts.ImpersonateGroups != "" {
+		commandArgs = append(commandArgs, "--as-group", s.opts.ImpersonateGroups)
+	}
+
+	return commandArgs
+}
+
+// executeKubectlCommand executes the kubectl command and returns the output
+func (s *kubectlSource) executeKubectlCommand(ctx context.Context, commandArgs []string) ([]byte, error) {
+	var res, cmdErr bytes.Buffer
+
+	out := exec.CommandContext(ctx, "kubectl", commandArgs...)
+	out.Stdout = &res
+	out.Stderr = &cmdErr
+	err := out.Run()
+	if err != nil {
+		if cmdErr.Len() > 0 {
+			return nil, fmt.Errorf("%sError: kubectl command failed: %w", cmdErr.String(), err)
+		}
+		return nil, fmt.Errorf("kubectl command failed: %w", err)
+	}
+
+	return res.Bytes(), nil
+}
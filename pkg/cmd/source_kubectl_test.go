@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildKubectlCommand(t *testing.T) {
+	tests := map[string]struct {
+		opts SourceOptions
+		want []string
+	}{
+		"no overrides": {
+			SourceOptions{},
+			[]string{"get", "secret", "test", "-o", "json"},
+		},
+		"all overrides": {
+			SourceOptions{
+				Context:           "ctx",
+				Impersonate:       "gopher",
+				ImpersonateGroups: "golovers",
+				KubeConfig:        "cfg",
+				Namespace:         "ns",
+			},
+			[]string{"get", "secret", "test", "-o", "json", "-n", "ns", "--context", "ctx", "--kubeconfig", "cfg", "--as", "gopher", "--as-group", "golovers"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := newKubectlSource(tt.opts).buildKubectlCommand("get", "secret", "test", "-o", "json")
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// newTestSecret creates a secret of the given type from plaintext values
+func newTestSecret(secretType SecretType, namespace, name string, values map[string]string) Secret {
+	data := SecretData{}
+	for k, v := range values {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	return Secret{Data: data, Metadata: Metadata{Name: name, Namespace: namespace}, Type: secretType}
+}
+
+// fakeSource is an in-memory SecretSource used to test without a cluster
+type fakeSource struct {
+	namespace  string
+	namespaces []string
+	secrets    []Secret
+	err        error
+}
+
+func (f *fakeSource) GetSecret(_ context.Context, name string) (Secret, error) {
+	if f.err != nil {
+		return Secret{}, f.err
+	}
+	for _, s := range f.secrets {
+		if s.Metadata.Name == name {
+			return s, nil
+		}
+	}
+	return Secret{}, fmt.Errorf("secrets %q not found", name)
+}
+
+func (f *fakeSource) ListSecrets(_ context.Context, opts ListOptions) (SecretList, error) {
+	if f.err != nil {
+		return SecretList{}, f.err
+	}
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return SecretList{}, err
+	}
+	items := []Secret{}
+	for _, s := range f.secrets {
+		inNamespace := opts.AllNamespaces || f.namespace == "" || s.Metadata.Namespace == f.namespace
+		if inNamespace && selector.Matches(labels.Set(s.Metadata.Labels)) {
+			items = append(items, s)
+		}
+	}
+	return SecretList{Items: items}, nil
+}
+
+func (f *fakeSource) ListSecretNames(ctx context.Context) ([]string, error) {
+	secretList, err := f.ListSecrets(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, s := range secretList.Items {
+		names = append(names, s.Metadata.Name)
+	}
+	return names, nil
+}
+
+func (f *fakeSource) ListNamespaces(_ context.Context) ([]string, error) {
+	return f.namespaces, f.err
+}
+
+func (f *fakeSource) ListKeys(ctx context.Context, name string) ([]string, error) {
+	secret, err := f.GetSecret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func TestNewSecretSource(t *testing.T) {
+	tests := map[string]struct {
+		backend string
+		wantErr error
+	}{
+		"default": {backend: ""},
+		"kubectl": {backend: BackendKubectl},
+		"invalid": {backend: "carrier-pigeon", wantErr: ErrUnknownBackend},
+		"api (no kubeconfig)": {
+			backend: BackendAPI,
+			wantErr: errors.New("failed to load kubeconfig"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", "/nonexistent/kubeconfig")
+
+			got, err := NewSecretSource(tt.backend, SourceOptions{})
+			if tt.wantErr != nil {
+				assert.Error(t, err)
+				assert.ErrorContains(t, err, tt.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, got)
+		})
+	}
+}
+
+func TestRetrieveWithSource(t *testing.T) {
+	source := &fakeSource{
+		secrets: []Secret{
+			{
+				Data:     SecretData{"key1": "dmFsdWUx", "key2": "dmFsdWUy"},
+				Metadata: Metadata{Name: "test", Namespace: "default"},
+				Type:     Opaque,
+			},
+			{
+				Data:     SecretData{"foo": "YmFy"},
+				Metadata: Metadata{Name: "gopher", Namespace: "default"},
+				Type:     Opaque,
+			},
+			{
+				// echo "helm-test" | gzip -c | base64 | base64
+				Data:     SecretData{"release": "SDRzSUFGb2FlR2NBQTh0SXpjblZMVWt0THVFQ0FQdWt3aHdLQUFBQQo="},
+				Metadata: Metadata{Name: "test3", Namespace: "helm"},
+				Type:     Helm,
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		opts     CommandOpts
+		source   SecretSource
+		feedkeys string
+		want     string
+		wantErr  error
+	}{
+		"all":             {opts: CommandOpts{secretName: "test", decodeAll: true}, source: source, want: "key1='value1'\nkey2='value2'\n"},
+		"single key":      {opts: CommandOpts{secretName: "test", secretKey: "key2"}, source: source, want: "value2\n"},
+		"only key":        {opts: CommandOpts{secretName: "gopher", quiet: true}, source: source, want: "bar\n"},
+		"only key info":   {opts: CommandOpts{secretName: "gopher"}, source: source, want: "Viewing only available key: foo\nbar\n"},
+		"helm":            {opts: CommandOpts{secretName: "test3", quiet: true}, source: source, want: "helm-test\n"},
+		"multiple keys":   {opts: CommandOpts{secretName: "test", secretKey: "key1", keys: []string{"key2"}}, source: source, want: "key1='value1'\nkey2='value2'\n"},
+		"interactive":     {opts: CommandOpts{decodeAll: true}, source: source, feedkeys: "\r", want: "key1='value1'\nkey2='value2'\n"},
+		"all namespaces":  {opts: CommandOpts{allNamespaces: true}, source: source, want: "# default/gopher\nfoo='bar'\n\n# default/test\nkey1='value1'\nkey2='value2'\n"},
+		"selector & name": {opts: CommandOpts{labelSelector: "app=payments", secretName: "test"}, source: source, wantErr: ErrSelectorWithSecretName},
+		"selector & raw":  {opts: CommandOpts{labelSelector: "app=payments", raw: true}, source: source, wantErr: fmt.Errorf("--raw: %w", ErrMultipleSecretsUnsupported)},
+		"no secrets":      {opts: CommandOpts{}, source: &fakeSource{}, wantErr: ErrNoSecretFound},
+		"not found":       {opts: CommandOpts{secretName: "nope"}, source: source, wantErr: errors.New(`secrets "nope" not found`)},
+		"backend failure": {opts: CommandOpts{secretName: "test"}, source: &fakeSource{err: errors.New("boom")}, wantErr: errors.New("boom")},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(tt.feedkeys))
+
+			opts := tt.opts
+			opts.outputFormat = "text"
+			opts.source = tt.source
+
+			err := opts.Retrieve(cmd)
+			if tt.wantErr != nil {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.wantErr.Error(), err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Contains(t, outBuf.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshPrivateKeyKey = "ssh-privatekey"
+
+	// opensshKeyMagic prefixes the payload of keys in the openssh-key-v1 format
+	opensshKeyMagic = "openssh-key-v1\x00"
+)
+
+// SSHKeyDetails represents the parsed view of a kubernetes.io/ssh-auth secret
+//
+// The private key itself is never part of the view. The public key details
+// are unavailable for passphrase protected keys in the legacy PEM format.
+type SSHKeyDetails struct {
+	Bits                int    `json:"bits,omitempty" yaml:"bits,omitempty"`
+	Comment             string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Fingerprint         string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	KeyType             string `json:"keyType,omitempty" yaml:"keyType,omitempty"`
+	PassphraseProtected bool   `json:"passphraseProtected" yaml:"passphraseProtected"`
+	PublicKey           string `json:"publicKey,omitempty" yaml:"publicKey,omitempty"`
+	Source              string `json:"source" yaml:"source"`
+}
+
+// opensshKey is the outer structure of a key in the openssh-key-v1 format
+type opensshKey struct {
+	CipherName   string
+	KdfName      string
+	KdfOpts      string
+	NumKeys      uint32
+	PubKey       []byte
+	PrivKeyBlock []byte
+}
+
+// opensshPrivateKey is the unencrypted private section of an openssh-key-v1 key
+type opensshPrivateKey struct {
+	Check1  uint32
+	Check2  uint32
+	Keytype string
+	Rest    []byte `ssh:"rest"`
+}
+
+// The key type specific fields precede the comment in the private section.
+type (
+	opensshRSAComment struct {
+		N, E, D, Iqmp, P, Q *big.Int
+		Comment             string
+		Pad                 []byte `ssh:"rest"`
+	}
+	opensshEd25519Comment struct {
+		Pub, Priv []byte
+		Comment   string
+		Pad       []byte `ssh:"rest"`
+	}
+	opensshECDSAComment struct {
+		Curve   string
+		Pub     []byte
+		D       *big.Int
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}
+)
+
+// parseSSHKeyDetails derives the public key details from the private key of a ssh-auth secret
+func parseSSHKeyDetails(secret Secret) (SSHKeyDetails, error) {
+	details := SSHKeyDetails{Source: sshPrivateKeyKey}
+
+	raw, err := decodeRawKey(secret, sshPrivateKeyKey)
+	if err != nil {
+		return details, err
+	}
+
+	var pub ssh.PublicKey
+	key, err := ssh.ParseRawPrivateKey(raw)
+	var passphraseErr *ssh.PassphraseMissingError
+	switch {
+	case errors.As(err, &passphraseErr):
+		details.PassphraseProtected = true
+		pub = passphraseErr.PublicKey
+	case err != nil:
+		return details, fmt.Errorf("failed to parse %s: %w", sshPrivateKeyKey, err)
+	default:
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			return details, fmt.Errorf("failed to derive public key: %w", err)
+		}
+		pub = signer.PublicKey()
+		details.Comment = opensshComment(raw)
+	}
+
+	if pub == nil {
+		return details, nil
+	}
+
+	details.KeyType = pub.Type()
+	details.Fingerprint = ssh.FingerprintSHA256(pub)
+	details.PublicKey = strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(pub)), "\n")
+	if details.Comment != "" {
+		details.PublicKey += " " + details.Comment
+	}
+	if cryptoPub, ok := pub.(ssh.CryptoPublicKey); ok {
+		details.Bits = publicKeyBits(cryptoPub.CryptoPublicKey())
+	}
+
+	return details, nil
+}
+
+// opensshComment returns the comment stored in the private section of an unencrypted openssh-key-v1 key
+func opensshComment(raw []byte) string {
+	block, _ := pem.Decode(raw)
+	if block == nil || !strings.HasPrefix(string(block.Bytes), opensshKeyMagic) {
+		return ""
+	}
+
+	var key opensshKey
+	if err := ssh.Unmarshal(block.Bytes[len(opensshKeyMagic):], &key); err != nil || key.CipherName != "none" {
+		return ""
+	}
+
+	var priv opensshPrivateKey
+	if err := ssh.Unmarshal(key.PrivKeyBlock, &priv); err != nil {
+		return ""
+	}
+
+	switch priv.Keytype {
+	case ssh.KeyAlgoRSA:
+		var k opensshRSAComment
+		if ssh.Unmarshal(priv.Rest, &k) == nil {
+			return k.Comment
+		}
+	case ssh.KeyAlgoED25519:
+		var k opensshEd25519Comment
+		if ssh.Unmarshal(priv.Rest, &k) == nil {
+			return k.Comment
+		}
+	case ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521:
+		var k opensshECDSAComment
+		if ssh.Unmarshal(priv.Rest, &k) == nil {
+			return k.Comment
+		}
+	}
+
+	return ""
+}
+
+// publicKeyBits returns the size of a public key in bits
+func publicKeyBits(pub any) int {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	default:
+		return 0
+	}
+}
+
+// renderText outputs the key details in a human readable form
+func (d SSHKeyDetails) renderText(outWriter io.Writer) error {
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(w, "%s\n", d.Source)
+	if d.KeyType == "" {
+		_, _ = fmt.Fprintf(w, "  Passphrase Protected:\t%s\n", checkResult(true, ""))
+		_, _ = fmt.Fprintf(w, "  Public Key:\tunavailable, the key is encrypted in the legacy PEM format\n")
+	} else {
+		_, _ = fmt.Fprintf(w, "  Type:\t%s\n", d.KeyType)
+		if d.Bits > 0 {
+			_, _ = fmt.Fprintf(w, "  Bits:\t%d\n", d.Bits)
+		}
+		_, _ = fmt.Fprintf(w, "  Fingerprint:\t%s\n", d.Fingerprint)
+		if d.Comment != "" {
+			_, _ = fmt.Fprintf(w, "  Comment:\t%s\n", d.Comment)
+		}
+		_, _ = fmt.Fprintf(w, "  Passphrase Protected:\t%s\n", checkResult(d.PassphraseProtected, ""))
+		_, _ = fmt.Fprintf(w, "  Public Key:\t%s\n", d.PublicKey)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
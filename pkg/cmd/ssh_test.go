@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseSSHKeyDetails(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	edBlock, err := ssh.MarshalPrivateKey(edKey, "deploy@ci")
+	assert.NoError(t, err)
+	rsaBlock, err := ssh.MarshalPrivateKey(rsaKey, "rsa@ci")
+	assert.NoError(t, err)
+	encryptedBlock, err := ssh.MarshalPrivateKeyWithPassphrase(ecKey, "secret@ci", []byte("passphrase"))
+	assert.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	assert.NoError(t, err)
+
+	edPub, err := ssh.NewPublicKey(edKey.Public())
+	assert.NoError(t, err)
+	ecPub, err := ssh.NewPublicKey(ecKey.Public())
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		secret  Secret
+		want    SSHKeyDetails
+		wantErr bool
+	}{
+		"openssh ed25519": {
+			secret: newTestSecret(SSHAuth, "default", "deploy-key", map[string]string{sshPrivateKeyKey: string(pem.EncodeToMemory(edBlock))}),
+			want: SSHKeyDetails{
+				Bits:        256,
+				Comment:     "deploy@ci",
+				Fingerprint: ssh.FingerprintSHA256(edPub),
+				KeyType:     ssh.KeyAlgoED25519,
+				PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(edPub))) + " deploy@ci",
+				Source:      sshPrivateKeyKey,
+			},
+		},
+		"openssh encrypted ecdsa": {
+			secret: newTestSecret(SSHAuth, "default", "deploy-key", map[string]string{sshPrivateKeyKey: string(pem.EncodeToMemory(encryptedBlock))}),
+			want: SSHKeyDetails{
+				Bits:                384,
+				Fingerprint:         ssh.FingerprintSHA256(ecPub),
+				KeyType:             ssh.KeyAlgoECDSA384,
+				PassphraseProtected: true,
+				PublicKey:           strings.TrimSpace(string(ssh.MarshalAuthorizedKey(ecPub))),
+				Source:              sshPrivateKeyKey,
+			},
+		},
+		"pem ecdsa": {
+			secret: newTestSecret(SSHAuth, "default", "deploy-key", map[string]string{sshPrivateKeyKey: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))}),
+			want: SSHKeyDetails{
+				Bits:        384,
+				Fingerprint: ssh.FingerprintSHA256(ecPub),
+				KeyType:     ssh.KeyAlgoECDSA384,
+				PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(ecPub))),
+				Source:      sshPrivateKeyKey,
+			},
+		},
+		"pem encrypted": {
+			secret: newTestSecret(SSHAuth, "default", "deploy-key", map[string]string{sshPrivateKeyKey: string(pem.EncodeToMemory(&pem.Block{
+				Type:    "RSA PRIVATE KEY",
+				Headers: map[string]string{"Proc-Type": "4,ENCRYPTED", "DEK-Info": "AES-128-CBC,00000000000000000000000000000000"},
+				Bytes:   []byte("encrypted"),
+			}))}),
+			want: SSHKeyDetails{PassphraseProtected: true, Source: sshPrivateKeyKey},
+		},
+		"invalid": {
+			secret:  newTestSecret(SSHAuth, "default", "deploy-key", map[string]string{sshPrivateKeyKey: string(pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: []byte("garbage")}))}),
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseSSHKeyDetails(tt.secret)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("rsa text", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := ProcessSecretWithOptions(&buf, &buf, nil, newTestSecret(SSHAuth, "default", "deploy-key", map[string]string{sshPrivateKeyKey: string(pem.EncodeToMemory(rsaBlock))}), ProcessOptions{Details: true, OutputFormat: "text"})
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "ssh-privatekey\n  Type:                  ssh-rsa\n  Bits:                  2048\n")
+		assert.Contains(t, buf.String(), "  Comment:               rsa@ci\n  Passphrase Protected:  no\n")
+		assert.NotContains(t, buf.String(), "PRIVATE KEY")
+	})
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	tlsCAKey   = "ca.crt"
+	tlsCertKey = "tls.crt"
+	tlsKeyKey  = "tls.key"
+)
+
+var (
+	// ErrNoCertificates is thrown if a value doesn't contain any PEM encoded certificates
+	ErrNoCertificates = errors.New("no PEM encoded certificates found")
+
+	// ErrNoPrivateKey is thrown if a value doesn't contain a PEM encoded private key
+	ErrNoPrivateKey = errors.New("no PEM encoded private key found")
+)
+
+// CertificateInfo represents the human relevant details of a x509 certificate
+type CertificateInfo struct {
+	DaysUntilExpiry    int       `json:"daysUntilExpiry" yaml:"daysUntilExpiry"`
+	Expired            bool      `json:"expired" yaml:"expired"`
+	IsCA               bool      `json:"isCA" yaml:"isCA"`
+	Issuer             string    `json:"issuer" yaml:"issuer"`
+	KeyAlgorithm       string    `json:"keyAlgorithm" yaml:"keyAlgorithm"`
+	NotAfter           time.Time `json:"notAfter" yaml:"notAfter"`
+	NotBefore          time.Time `json:"notBefore" yaml:"notBefore"`
+	SANs               []string  `json:"sans,omitempty" yaml:"sans,omitempty"`
+	SerialNumber       string    `json:"serialNumber" yaml:"serialNumber"`
+	SignatureAlgorithm string    `json:"signatureAlgorithm" yaml:"signatureAlgorithm"`
+	Source             string    `json:"source" yaml:"source"`
+	Subject            string    `json:"subject" yaml:"subject"`
+}
+
+// TLSDetails represents the parsed view of a kubernetes.io/tls secret
+//
+// KeyMatchesCertificate and ChainValid are only set if the secret contains
+// a private key or a CA bundle respectively.
+type TLSDetails struct {
+	Certificates          []CertificateInfo `json:"certificates" yaml:"certificates"`
+	ChainError            string            `json:"chainError,omitempty" yaml:"chainError,omitempty"`
+	ChainValid            *bool             `json:"chainValid,omitempty" yaml:"chainValid,omitempty"`
+	KeyError              string            `json:"keyError,omitempty" yaml:"keyError,omitempty"`
+	KeyMatchesCertificate *bool             `json:"keyMatchesCertificate,omitempty" yaml:"keyMatchesCertificate,omitempty"`
+}
+
+// parseTLSDetails parses the certificates of a TLS secret and verifies key and chain
+func parseTLSDetails(secret Secret, now time.Time) (TLSDetails, error) {
+	var details TLSDetails
+
+	certPEM, err := decodeRawKey(secret, tlsCertKey)
+	if err != nil {
+		return details, err
+	}
+
+	certs, err := parseCertificates(certPEM)
+	if err != nil {
+		return details, fmt.Errorf("failed to parse %s: %w", tlsCertKey, err)
+	}
+
+	for i, cert := range certs {
+		details.Certificates = append(details.Certificates, newCertificateInfo(cert, certSource(tlsCertKey, i, len(certs)), now))
+	}
+
+	var caCerts []*x509.Certificate
+	if _, ok := secret.Data[tlsCAKey]; ok {
+		caPEM, err := decodeRawKey(secret, tlsCAKey)
+		if err != nil {
+			return details, err
+		}
+		if caCerts, err = parseCertificates(caPEM); err != nil {
+			return details, fmt.Errorf("failed to parse %s: %w", tlsCAKey, err)
+		}
+		for i, cert := range caCerts {
+			details.Certificates = append(details.Certificates, newCertificateInfo(cert, certSource(tlsCAKey, i, len(caCerts)), now))
+		}
+	}
+
+	if _, ok := secret.Data[tlsKeyKey]; ok {
+		keyPEM, err := decodeRawKey(secret, tlsKeyKey)
+		if err != nil {
+			return details, err
+		}
+		matches, err := privateKeyMatches(keyPEM, certs[0])
+		if err != nil {
+			details.KeyError = err.Error()
+		}
+		details.KeyMatchesCertificate = &matches
+	}
+
+	if len(caCerts) > 0 {
+		err := verifyChain(certs, caCerts, now)
+		valid := err == nil
+		if err != nil {
+			details.ChainError = err.Error()
+		}
+		details.ChainValid = &valid
+	}
+
+	return details, nil
+}
+
+// renderText outputs the certificate details in a human readable form
+func (d TLSDetails) renderText(outWriter io.Writer) error {
+	w := tabwriter.NewWriter(outWriter, 0, 0, 2, ' ', 0)
+
+	for i, c := range d.Certificates {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "%s\n", c.Source)
+		_, _ = fmt.Fprintf(w, "  Subject:\t%s\n", c.Subject)
+		_, _ = fmt.Fprintf(w, "  Issuer:\t%s\n", c.Issuer)
+		if len(c.SANs) > 0 {
+			_, _ = fmt.Fprintf(w, "  SANs:\t%s\n", strings.Join(c.SANs, ", "))
+		}
+		_, _ = fmt.Fprintf(w, "  Serial:\t%s\n", c.SerialNumber)
+		_, _ = fmt.Fprintf(w, "  Key Algorithm:\t%s\n", c.KeyAlgorithm)
+		_, _ = fmt.Fprintf(w, "  Signature Algorithm:\t%s\n", c.SignatureAlgorithm)
+		_, _ = fmt.Fprintf(w, "  CA:\t%t\n", c.IsCA)
+		_, _ = fmt.Fprintf(w, "  Not Before:\t%s\n", c.NotBefore.Format(time.RFC3339))
+		_, _ = fmt.Fprintf(w, "  Not After:\t%s (%s)\n", c.NotAfter.Format(time.RFC3339), expiryDescription(c.DaysUntilExpiry, c.Expired))
+	}
+
+	if d.KeyMatchesCertificate != nil {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintf(w, "Key matches certificate:\t%s\n", checkResult(*d.KeyMatchesCertificate, d.KeyError))
+	}
+	if d.ChainValid != nil {
+		if d.KeyMatchesCertificate == nil {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "Chain valid (%s):\t%s\n", tlsCAKey, checkResult(*d.ChainValid, d.ChainError))
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// decodeRawKey decodes the value of a key without any type specific post-processing
+func decodeRawKey(secret Secret, key string) ([]byte, error) {
+	v, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSecretKeyNotFound, key)
+	}
+
+	decoded, err := decodeBase64(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key %s: %w", key, err)
+	}
+
+	return []byte(decoded), nil
+}
+
+// parseCertificates parses all PEM encoded certificates, skipping other PEM blocks
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, ErrNoCertificates
+	}
+
+	return certs, nil
+}
+
+// newCertificateInfo extracts the details of a certificate relative to the given time
+func newCertificateInfo(cert *x509.Certificate, source string, now time.Time) CertificateInfo {
+	var sans []string
+	for _, dns := range cert.DNSNames {
+		sans = append(sans, "DNS:"+dns)
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, "IP:"+ip.String())
+	}
+	for _, email := range cert.EmailAddresses {
+		sans = append(sans, "email:"+email)
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, "URI:"+uri.String())
+	}
+
+	return CertificateInfo{
+		DaysUntilExpiry:    int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24)),
+		Expired:            now.After(cert.NotAfter),
+		IsCA:               cert.IsCA,
+		Issuer:             cert.Issuer.String(),
+		KeyAlgorithm:       publicKeyDescription(cert.PublicKey),
+		NotAfter:           cert.NotAfter.UTC(),
+		NotBefore:          cert.NotBefore.UTC(),
+		SANs:               sans,
+		SerialNumber:       formatSerial(cert.SerialNumber.Bytes()),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		Source:             source,
+		Subject:            cert.Subject.String(),
+	}
+}
+
+// certSource names the origin of a certificate, including its position in a bundle
+func certSource(key string, idx, total int) string {
+	if total == 1 {
+		return key
+	}
+	return fmt.Sprintf("%s[%d]", key, idx)
+}
+
+// publicKeyDescription describes the algorithm and size of a public key
+func publicKeyDescription(pub any) string {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("%T", pub)
+	}
+}
+
+// formatSerial formats a serial number as colon separated hex bytes
+func formatSerial(b []byte) string {
+	if len(b) == 0 {
+		return "00"
+	}
+
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[i] = fmt.Sprintf("%02X", v)
+	}
+	return strings.Join(parts, ":")
+}
+
+// privateKeyMatches reports whether the PEM encoded private key belongs to the certificate
+func privateKeyMatches(keyPEM []byte, cert *x509.Certificate) (bool, error) {
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return false, err
+	}
+
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return false, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return pub.Equal(cert.PublicKey), nil
+}
+
+// parsePrivateKey parses the first PEM encoded PKCS#1, PKCS#8 or SEC 1 private key
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, ErrNoPrivateKey
+		}
+
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			return x509.ParseECPrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			signer, ok := key.(crypto.Signer)
+			if !ok {
+				return nil, fmt.Errorf("unsupported private key type %T", key)
+			}
+			return signer, nil
+		}
+	}
+}
+
+// verifyChain verifies the leaf certificate against the CA bundle using the remaining certificates as intermediates
+func verifyChain(certs, caCerts []*x509.Certificate, now time.Time) error {
+	roots := x509.NewCertPool()
+	for _, c := range caCerts {
+		roots.AddCert(c)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		CurrentTime:   now,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		Roots:         roots,
+	})
+
+	return err
+}
+
+// expiryDescription describes the time until or since expiry in days
+func expiryDescription(days int, expired bool) string {
+	if expired {
+		return fmt.Sprintf("expired %s ago", pluralDays(-days))
+	}
+	return fmt.Sprintf("expires in %s", pluralDays(days))
+}
+
+// pluralDays formats a number of days
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// checkResult describes the outcome of a verification
+func checkResult(ok bool, reason string) string {
+	switch {
+	case ok:
+		return "yes"
+	case reason != "":
+		return "no (" + reason + ")"
+	default:
+		return "no"
+	}
+}
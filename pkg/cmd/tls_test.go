@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var tlsTestNow = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// testCert is a generated certificate together with its key
+type testCert struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate signed by the parent or a self-signed CA if parent is nil
+func newTestCert(t *testing.T, cn string, serial int64, notAfter time.Time, parent *testCert) testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+	}
+
+	signer, signerCert := key, tmpl
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		tmpl.DNSNames = []string{cn, "www." + cn}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		signer, signerCert = parent.key, parent.cert
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signerCert, &key.PublicKey, signer)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return testCert{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestParseTLSDetails(t *testing.T) {
+	ca := newTestCert(t, "Test CA", 1, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	otherCA := newTestCert(t, "Other CA", 2, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	leaf := newTestCert(t, "example.com", 258, time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC), &ca)
+	expired := newTestCert(t, "old.example.com", 3, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), &ca)
+
+	yes, no := true, false
+
+	tests := map[string]struct {
+		secret      Secret
+		wantCerts   int
+		wantKey     *bool
+		wantChain   *bool
+		wantExpired bool
+		wantErr     error
+	}{
+		"leaf with key and ca": {
+			secret:    newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: string(leaf.certPEM), tlsKeyKey: string(leaf.keyPEM), tlsCAKey: string(ca.certPEM)}),
+			wantCerts: 2,
+			wantKey:   &yes,
+			wantChain: &yes,
+		},
+		"mismatching key": {
+			secret:    newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: string(leaf.certPEM), tlsKeyKey: string(ca.keyPEM)}),
+			wantCerts: 1,
+			wantKey:   &no,
+		},
+		"untrusted chain": {
+			secret:    newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: string(leaf.certPEM), tlsCAKey: string(otherCA.certPEM)}),
+			wantCerts: 2,
+			wantChain: &no,
+		},
+		"expired leaf": {
+			secret:      newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: string(expired.certPEM), tlsCAKey: string(ca.certPEM)}),
+			wantCerts:   2,
+			wantChain:   &no,
+			wantExpired: true,
+		},
+		"no certificate": {
+			secret:  newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: "garbage"}),
+			wantErr: ErrNoCertificates,
+		},
+		"missing tls.crt": {
+			secret:  newTestSecret(TLS, "default", "tls", map[string]string{tlsKeyKey: string(leaf.keyPEM)}),
+			wantErr: ErrSecretKeyNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseTLSDetails(tt.secret, tlsTestNow)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, got.Certificates, tt.wantCerts)
+			assert.Equal(t, tt.wantKey, got.KeyMatchesCertificate)
+			assert.Equal(t, tt.wantChain, got.ChainValid)
+			assert.Equal(t, tt.wantExpired, got.Certificates[0].Expired)
+		})
+	}
+}
+
+func TestTLSDetailsOutput(t *testing.T) {
+	ca := newTestCert(t, "Test CA", 1, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	leaf := newTestCert(t, "example.com", 258, time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC), &ca)
+	secret := newTestSecret(TLS, "default", "tls", map[string]string{tlsCertKey: string(leaf.certPEM), tlsKeyKey: string(leaf.keyPEM), tlsCAKey: string(ca.certPEM)})
+
+	details, err := parseTLSDetails(secret, tlsTestNow)
+	assert.NoError(t, err)
+
+	leafInfo := details.Certificates[0]
+	assert.Equal(t, "CN=example.com", leafInfo.Subject)
+	assert.Equal(t, "CN=Test CA", leafInfo.Issuer)
+	assert.Equal(t, []string{"DNS:example.com", "DNS:www.example.com"}, leafInfo.SANs)
+	assert.Equal(t, "01:02", leafInfo.SerialNumber)
+	assert.Equal(t, "ECDSA P-256", leafInfo.KeyAlgorithm)
+	assert.Equal(t, 10, leafInfo.DaysUntilExpiry)
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, outputFormattedSecret(&buf, secret, details, "text"))
+		assert.Contains(t, buf.String(), "tls.crt\n  Subject:              CN=example.com\n")
+		assert.Contains(t, buf.String(), "  Not After:            2025-06-11T00:00:00Z (expires in 10 days)\n")
+		assert.Contains(t, buf.String(), "Key matches certificate:  yes\n")
+		assert.Contains(t, buf.String(), "Chain valid (ca.crt):     yes\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, outputFormattedSecret(&buf, secret, details, "json"))
+		assert.Contains(t, buf.String(), `"keyMatchesCertificate": true`)
+		assert.Contains(t, buf.String(), `"daysUntilExpiry": 10`)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, outputFormattedSecret(&buf, secret, details, "yaml"))
+		assert.Contains(t, buf.String(), "chainValid: true")
+		assert.Contains(t, buf.String(), "source: ca.crt")
+	})
+}
+
+func TestProcessSecretDetails(t *testing.T) {
+	var buf bytes.Buffer
+	err := ProcessSecretWithOptions(&buf, &buf, nil, Secret{Data: secretSingle, Type: Opaque}, ProcessOptions{Details: true, OutputFormat: "text"})
+	assert.ErrorIs(t, err, ErrNoDetailsView)
+}
@@ -17,8 +17,9 @@ type SecretData map[string]string
 
 // Metadata represents the metadata of a secret
 type Metadata struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
 }
 
 // SecretType represents the type of a secret
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// defaultUnwrapDepth is the maximum number of layers peeled off a value by default
+const defaultUnwrapDepth = 5
+
+// maxUnwrapSize caps decompressed layers, a small secret value could otherwise expand into gigabytes
+const maxUnwrapSize = 4 << 20
+
+// Names of the layers reported in the unwrap chain
+const (
+	layerBase64    = "base64"
+	layerBase64URL = "base64url"
+	layerGzip      = "gzip"
+	layerJSON      = "json"
+	layerJSONQuote = "json-string"
+	layerTruncated = "truncated"
+	layerZlib      = "zlib"
+)
+
+// unwrapStep is a removed layer of encoding and the value below it
+type unwrapStep struct {
+	layer string
+	value string
+}
+
+// unwrapper detects and removes a layer of encoding, it returns no steps if the layer doesn't apply
+//
+// An unwrapper may return several steps if detecting its layer already
+// removed the one below, so the work isn't repeated.
+type unwrapper func(value string) []unwrapStep
+
+// unwrappers are tried in order on every iteration, compression first since its magic bytes are unambiguous
+var unwrappers = []unwrapper{
+	singleStep(layerGzip, unwrapGzip),
+	singleStep(layerZlib, unwrapZlib),
+	singleStep(layerJSONQuote, unwrapJSONString),
+	func(v string) []unwrapStep { return unwrapBase64(v, layerBase64, base64.StdEncoding) },
+	func(v string) []unwrapStep { return unwrapBase64(v, layerBase64URL, base64.RawURLEncoding) },
+}
+
+// singleStep turns a function removing one layer into an unwrapper
+func singleStep(layer string, unwrap func(value string) (string, bool)) unwrapper {
+	return func(value string) []unwrapStep {
+		if unwrapped, ok := unwrap(value); ok {
+			return []unwrapStep{{layer: layer, value: unwrapped}}
+		}
+		return nil
+	}
+}
+
+// unwrapValue peels nested encodings off a decoded value up to the given depth
+//
+// It returns the innermost value and the applied layers. If the result is a
+// JSON document, `json` is appended to the layers to describe the content.
+// A layer exceeding maxUnwrapSize is cut off, marked as `truncated` and
+// unwrapping stops. A depth of zero uses the default depth.
+func unwrapValue(value string, depth int) (string, []string) {
+	var layers []string
+
+	if depth <= 0 {
+		depth = defaultUnwrapDepth
+	}
+
+	for len(layers) < depth {
+		var steps []unwrapStep
+		for _, u := range unwrappers {
+			if steps = u(value); len(steps) > 0 {
+				break
+			}
+		}
+		if len(steps) == 0 {
+			break
+		}
+
+		for _, step := range steps {
+			if len(layers) == depth {
+				break
+			}
+			value = step.value
+			layers = append(layers, step.layer)
+			if len(value) > maxUnwrapSize {
+				return value[:maxUnwrapSize], append(layers, layerTruncated)
+			}
+		}
+	}
+
+	if trimmed := strings.TrimSpace(value); (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		layers = append(layers, layerJSON)
+	}
+
+	return value, layers
+}
+
+// unwrapGzip decompresses gzip data identified by its magic bytes
+func unwrapGzip(value string) (string, bool) {
+	if !strings.HasPrefix(value, "\x1f\x8b") {
+		return "", false
+	}
+
+	gz, err := gzip.NewReader(strings.NewReader(value))
+	if err != nil {
+		return "", false
+	}
+	defer func() { _ = gz.Close() }()
+
+	return readAllLayer(gz)
+}
+
+// unwrapZlib decompresses zlib data identified by its header checksum
+func unwrapZlib(value string) (string, bool) {
+	if len(value) < 2 || value[0]&0x0f != 8 || (uint16(value[0])<<8|uint16(value[1]))%31 != 0 {
+		return "", false
+	}
+
+	zr, err := zlib.NewReader(strings.NewReader(value))
+	if err != nil {
+		return "", false
+	}
+	defer func() { _ = zr.Close() }()
+
+	return readAllLayer(zr)
+}
+
+// readAllLayer reads a decompressed layer, a corrupt stream means the layer doesn't apply
+//
+// At most one byte more than maxUnwrapSize is read, so the caller can tell
+// that the layer was cut off.
+func readAllLayer(r io.Reader) (string, bool) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(r, maxUnwrapSize+1)); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// unwrapJSONString removes JSON string quoting and escaping
+func unwrapJSONString(value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) < 2 || trimmed[0] != '"' || trimmed[len(trimmed)-1] != '"' {
+		return "", false
+	}
+
+	var s string
+	if err := json.Unmarshal([]byte(trimmed), &s); err != nil {
+		return "", false
+	}
+
+	return s, true
+}
+
+// unwrapBase64 decodes base64 data with the given encoding
+//
+// Plain words are frequently valid base64 as well, so the layer only applies
+// if the result is either printable or a compressed stream. The stream is
+// decompressed to detect it, that result is returned as the next step.
+func unwrapBase64(value, layer string, encoding *base64.Encoding) []unwrapStep {
+	trimmed := strings.TrimSpace(value)
+	if encoding == base64.RawURLEncoding {
+		trimmed = strings.TrimRight(trimmed, "=")
+	}
+	if len(trimmed) < 4 {
+		return nil
+	}
+
+	decoded, err := encoding.DecodeString(trimmed)
+	if err != nil || len(decoded) == 0 {
+		return nil
+	}
+
+	s := string(decoded)
+	if !isBinary(s) {
+		return []unwrapStep{{layer: layer, value: s}}
+	}
+	if inflated, ok := unwrapGzip(s); ok {
+		return []unwrapStep{{layer: layer, value: s}, {layer: layerGzip, value: inflated}}
+	}
+	if inflated, ok := unwrapZlib(s); ok {
+		return []unwrapStep{{layer: layer, value: s}, {layer: layerZlib, value: inflated}}
+	}
+
+	return nil
+}
+
+// formatLayers describes the unwrap chain of a value
+func formatLayers(layers []string) string {
+	return strings.Join(layers, " -> ")
+}
+
+// reportLayers writes the unwrap chain of each value to the error writer
+func reportLayers(errWriter io.Writer, decodedData []KeyValue) error {
+	for _, kv := range decodedData {
+		if len(kv.Layers) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(errWriter, "Unwrapped %s: %s\n", kv.Key, formatLayers(kv.Layers)); err != nil {
+			return fmt.Errorf("failed to write to stderr: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipString(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	return buf.String()
+}
+
+func zlibString(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, err := zw.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	return buf.String()
+}
+
+func TestUnwrapValue(t *testing.T) {
+	doc := `{"user":"admin","password":"hunter2"}`
+	b64 := base64.StdEncoding.EncodeToString
+
+	tests := map[string]struct {
+		value      string
+		depth      int
+		want       string
+		wantLayers []string
+	}{
+		"plain text": {
+			value: "password",
+			want:  "password",
+		},
+		"base64 gzip json": {
+			value:      b64([]byte(gzipString(t, doc))),
+			want:       doc,
+			wantLayers: []string{layerBase64, layerGzip, layerJSON},
+		},
+		"base64url zlib": {
+			value:      base64.RawURLEncoding.EncodeToString([]byte(zlibString(t, "hello world ~~~ ???"))),
+			want:       "hello world ~~~ ???",
+			wantLayers: []string{layerBase64URL, layerZlib},
+		},
+		"json string escaping": {
+			value:      `"{\"user\":\"admin\"}"`,
+			want:       `{"user":"admin"}`,
+			wantLayers: []string{layerJSONQuote, layerJSON},
+		},
+		"double base64": {
+			value:      b64([]byte(b64([]byte("connection string\n")))),
+			want:       "connection string\n",
+			wantLayers: []string{layerBase64, layerBase64},
+		},
+		"depth limit": {
+			value:      b64([]byte(b64([]byte("connection string\n")))),
+			depth:      1,
+			want:       b64([]byte("connection string\n")),
+			wantLayers: []string{layerBase64},
+		},
+		"base64 of binary is kept": {
+			value: b64([]byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}),
+			want:  b64([]byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}),
+		},
+		"decompression is capped": {
+			value:      gzipString(t, strings.Repeat("a", maxUnwrapSize+1024)),
+			want:       strings.Repeat("a", maxUnwrapSize),
+			wantLayers: []string{layerGzip, layerTruncated},
+		},
+		"depth limit within a compressed layer": {
+			value:      b64([]byte(gzipString(t, doc))),
+			depth:      1,
+			want:       gzipString(t, doc),
+			wantLayers: []string{layerBase64},
+		},
+		"decompression below base64 is capped": {
+			value:      b64([]byte(zlibString(t, strings.Repeat("a", maxUnwrapSize+1024)))),
+			want:       strings.Repeat("a", maxUnwrapSize),
+			wantLayers: []string{layerBase64, layerZlib, layerTruncated},
+		},
+		"corrupt gzip is kept": {
+			value: "\x1f\x8bnot gzip",
+			want:  "\x1f\x8bnot gzip",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, layers := unwrapValue(tt.value, tt.depth)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantLayers, layers)
+		})
+	}
+}
+
+func TestProcessSecretUnwrap(t *testing.T) {
+	nested := base64.StdEncoding.EncodeToString([]byte(gzipString(t, `{"token":"abc"}`)))
+	secret := Secret{
+		Data: SecretData{
+			"config":   base64.StdEncoding.EncodeToString([]byte(nested)),
+			"password": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+		},
+		Metadata: Metadata{Name: "app", Namespace: "default"},
+		Type:     Opaque,
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var outBuf, errBuf bytes.Buffer
+		err := ProcessSecretWithOptions(&outBuf, &errBuf, nil, secret, ProcessOptions{OutputFormat: "text", SecretKey: "config", Unwrap: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "{\"token\":\"abc\"}\n", outBuf.String())
+		assert.Equal(t, "Unwrapped config: base64 -> gzip -> json\n", errBuf.String())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var outBuf, errBuf bytes.Buffer
+		err := ProcessSecretWithOptions(&outBuf, &errBuf, nil, secret, ProcessOptions{DecodeAll: true, OutputFormat: "yaml", Unwrap: true})
+		assert.NoError(t, err)
+		assert.Equal(t, `data:
+    - key: config
+      layers:
+        - base64
+        - gzip
+        - json
+      value: '{"token":"abc"}'
+    - key: password
+      value: hunter2
+name: app
+namespace: default
+type: Opaque
+`, outBuf.String())
+		assert.Empty(t, errBuf.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var outBuf, errBuf bytes.Buffer
+		err := ProcessSecretWithOptions(&outBuf, &errBuf, nil, secret, ProcessOptions{OutputFormat: "text", SecretKey: "config"})
+		assert.NoError(t, err)
+		assert.Equal(t, nested+"\n", outBuf.String())
+	})
+}
@@ -1,12 +1,14 @@
 package cmd
 
 import (
-	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
-	"os/exec"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
@@ -16,9 +18,14 @@ import (
 )
 
 // KeyValue represents a key-value pair for sorted output
+//
+// Binary values are base64 encoded and marked with the encoding. Layers lists
+// the nested encodings that were removed from the value if unwrapping is enabled.
 type KeyValue struct {
-	Key   string `json:"key" yaml:"key"`
-	Value string `json:"value" yaml:"value"`
+	Encoding string   `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Key      string   `json:"key" yaml:"key"`
+	Layers   []string `json:"layers,omitempty" yaml:"layers,omitempty"`
+	Value    string   `json:"value" yaml:"value"`
 }
 
 const (
@@ -29,9 +36,20 @@ const (
 	# decode specific entry
 	%[1]s view-secret <secret> <key>
 
+	# decode a subset of the entries
+	%[1]s view-secret <secret> <key1> <key2>
+	%[1]s view-secret <secret> --keys key1,key2
+
+	# decode all entries except some
+	%[1]s view-secret <secret> -a --exclude-keys ca.crt
+
 	# decode all contents
 	%[1]s view-secret <secret> -a/--all
 
+	# decode every secret matching a label or field selector, optionally across all namespaces
+	%[1]s view-secret -l app=payments [-A]
+	%[1]s view-secret --field-selector type=kubernetes.io/basic-auth -o yaml
+
 	# print keys for secret in different namespace
 	%[1]s view-secret <secret> -n/--namespace <ns>
 
@@ -46,13 +64,94 @@ const (
 
 	# output in json (or yaml) instead of text
 	%[1]s view-secret <secret> -o/--output json
+
+	# extract a field from a value holding a JSON or YAML document
+	%[1]s view-secret <pull-secret> .dockerconfigjson --query '.auths["ghcr.io"].username'
+
+	# peel nested encodings like base64, gzip or zlib off the values and report the chain per key
+	%[1]s view-secret <secret> -a --unwrap [--unwrap-depth 5]
+
+	# export all values as shell-safe environment variables
+	eval "$(%[1]s view-secret <secret> -a -o env)"
+
+	# write a dotenv file for docker compose or direnv (or an --env-file for docker run with docker-env-file)
+	%[1]s view-secret <secret> -a -o dotenv > .env
+
+	# write every key to its own file like a secret volume mount, replacing existing files
+	%[1]s view-secret <secret> --to-dir ./secret [--force]
+
+	# copy a value to the clipboard instead of printing it, clearing it again after 30s
+	%[1]s view-secret <secret> <key> --clipboard [--clipboard-clear 30s]
+
+	# compare values between clusters or with a password manager without revealing them
+	%[1]s view-secret <secret> -a --hash sha256 [--hmac-key-file ./key]
+
+	# write the exact bytes of a value, e.g. a binary keystore, to a file
+	%[1]s view-secret <secret> <key> --raw > keystore.jks
+
+	# talk to the API server directly instead of shelling out to kubectl
+	%[1]s view-secret <secret> --backend api
+
+	# decode secrets from manifest files, directories or stdin without a cluster
+	%[1]s view-secret <secret> -f/--filename secret.yaml
+	kubectl get secret <secret> -o yaml | %[1]s view-secret <secret> -f -
+
+	# print a single part of a helm release, e.g. the user supplied values
+	%[1]s view-secret sh.helm.release.v1.<release>.v<revision> --helm-part values -o yaml
+
+	# compare a secret key by key between two contexts, namespaces or kubeconfigs
+	%[1]s view-secret diff <secret> -c staging --other-context production [--values]
+
+	# check whether the live secret matches a local manifest before applying it
+	%[1]s view-secret diff <secret> -f secret.yaml [--values]
+
+	# find the secrets containing a leaked credential without printing any values
+	%[1]s view-secret grep -A < leaked.txt
+
+	# list the secrets holding keys named like a glob across all namespaces, without decoding values
+	%[1]s view-secret find-keys '*PASSWORD*' -i -A
+
+	# view a secret named like a subcommand, e.g. diff, by passing the flags first and the name after --
+	%[1]s view-secret -n <ns> -- diff [<key>]
+
+	# diff the values and rendered manifests of the last two revisions of a helm release
+	%[1]s view-secret helm-diff <release>
+
+	# show subject, SANs & expiry of the certificates in a TLS secret and verify key & chain
+	%[1]s view-secret <tls-secret> --details
+
+	# decode the claims of a service account token and check whether it expired
+	%[1]s view-secret <sa-token-secret> --details
+
+	# print the fingerprint & public key of a deploy key without revealing the private key
+	%[1]s view-secret <ssh-secret> --details
+
+	# audit a bootstrap token used to join nodes
+	%[1]s view-secret bootstrap-token-<id> -n kube-system --details
+
+	# list the decoded credentials per registry of an image pull secret, hiding passwords
+	%[1]s view-secret <pull-secret> --details --registry ghcr.io --mask
+
+	# hide values while screen sharing, revealing only the first and last 4 characters (or only the length)
+	%[1]s view-secret <secret> -a --mask=partial [--mask-chars 4]
+	%[1]s view-secret <secret> -a --mask=length
+
+	# browse contexts, namespaces, secrets and keys in a full-screen terminal UI with values masked until revealed
+	%[1]s view-secret --browse [-c <ctx>] [-n <ns>]
+
+	# report certificates expiring soon across all namespaces
+	%[1]s view-secret cert-scan -A --warn 30 --critical 7
 `
 
-	secretDescription     = "Found %d keys in secret %q. Choose one or select 'all' to view."
-	secretListDescription = "Found %d secrets. Choose one."
-	secretListTitle       = "Available Secrets"
-	secretTitle           = "Secret Data"
-	singleKeyDescription  = "Viewing only available key: %[1]s"
+	copyKeyDescription        = "Found %d keys in secret %q. Choose one to copy."
+	revealDescription         = "Values are masked. Toggle the keys to show in plaintext."
+	revealTitle               = "Reveal Keys"
+	secretDescription         = "Found %d keys in secret %q. Choose one or select 'all' to view, toggle keys to view several."
+	secretListDescription     = "Found %d secrets. Choose one."
+	secretListTitle           = "Available Secrets"
+	secretTitle               = "Secret Data"
+	shadowedSecretDescription = "Viewing secret %q which shadows the %s subcommand"
+	singleKeyDescription      = "Viewing only available key: %[1]s"
 )
 
 var (
@@ -66,18 +165,60 @@ var (
 	ErrSecretKeyNotFound = errors.New("provided key not found in secret")
 )
 
+// ExitError is returned if the command should terminate with a specific exit code
+//
+// Err optionally holds the failure behind the exit code, its message is used
+// unless a Message is set.
+type ExitError struct {
+	Code    int
+	Err     error
+	Message string
+}
+
+// Error implements the error interface
+func (e *ExitError) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the failure behind the exit code
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 // CommandOpts is the struct holding common properties
 type CommandOpts struct {
-	customContext       string
-	customNamespace     string
-	decodeAll           bool
-	impersonateAs       string
-	impersonateAsGroups string
-	kubeConfig          string
-	outputFormat        string
-	quiet               bool
-	secretKey           string
-	secretName          string
+	sourceFlags
+
+	allNamespaces  bool
+	browse         bool
+	clipboard      bool
+	clipboardClear time.Duration
+	decodeAll      bool
+	details        bool
+	excludeKeys    []string
+	fieldSelector  string
+	force          bool
+	hash           string
+	helmPart       string
+	hmacKeyFile    string
+	keys           []string
+	labelSelector  string
+	mask           string
+	maskChars      int
+	outputFormat   string
+	query          string
+	quiet          bool
+	raw            bool
+	registry       string
+	secretKey      string
+	secretName     string
+	source         SecretSource
+	toDir          string
+	unwrap         bool
+	unwrapDepth    int
 }
 
 // NewCmdViewSecret creates the cobra command to be executed
@@ -88,11 +229,11 @@ func NewCmdViewSecret() *cobra.Command {
 	res := &CommandOpts{}
 
 	cmd := &cobra.Command{
-		Args:         cobra.RangeArgs(0, 2),
+		Args:         cobra.ArbitraryArgs,
 		Example:      fmt.Sprintf(example, "kubectl"),
 		Short:        "Decode a kubernetes secret by name & key in the current context/cluster/namespace",
 		SilenceUsage: true,
-		Use:          "view-secret [secret-name] [secret-key]",
+		Use:          "view-secret [secret-name] [secret-key...]",
 		RunE: func(c *cobra.Command, args []string) error {
 			res.ParseArgs(args)
 			if err := res.Retrieve(c); err != nil {
@@ -105,142 +246,237 @@ func NewCmdViewSecret() *cobra.Command {
 
 	cmd.Flags().
 		BoolVarP(&res.decodeAll, "all", "a", res.decodeAll, "if true, decodes all secrets without specifying the individual secret keys")
+	cmd.Flags().StringSliceVar(&res.keys, "keys", res.keys, "decodes only these keys, e.g. username,password")
+	cmd.Flags().StringSliceVar(&res.excludeKeys, "exclude-keys", res.excludeKeys, "leaves out these keys, e.g. ca.crt")
 	cmd.Flags().BoolVarP(&res.quiet, "quiet", "q", res.quiet, "if true, suppresses info output")
-	cmd.Flags().
-		StringVarP(&res.customNamespace, "namespace", "n", res.customNamespace, "override the namespace defined in the current context")
-	cmd.Flags().StringVarP(&res.customContext, "context", "c", res.customContext, "override the current context")
-	cmd.Flags().StringVarP(&res.kubeConfig, "kubeconfig", "k", res.kubeConfig, "explicitly provide the kubeconfig to use")
-	cmd.Flags().StringVar(&res.impersonateAs, "as", res.impersonateAs, "Username to impersonate for the operation. User could be a regular user or a service account in a namespace.")
-	cmd.Flags().StringVar(&res.impersonateAsGroups, "as-group", res.impersonateAsGroups, "Groups to impersonate for the operation. Multipe groups can be specified by comma separated.")
-	cmd.Flags().StringVarP(&res.outputFormat, "output", "o", "text", "output format: text, json, yaml")
+	cmd.Flags().StringVarP(&res.labelSelector, "selector", "l", res.labelSelector, "decodes all secrets matching this label selector, e.g. app=payments, implies --all")
+	cmd.Flags().StringVar(&res.fieldSelector, "field-selector", res.fieldSelector, "decodes all secrets matching this field selector, e.g. type=kubernetes.io/tls, implies --all")
+	cmd.Flags().BoolVarP(&res.allNamespaces, "all-namespaces", "A", res.allNamespaces, "if true, decodes the matching secrets across all namespaces, implies --all")
+	cmd.Flags().StringVar(&res.query, "query", res.query, "jq-style path extracted from values holding a JSON or YAML document, e.g. '.auths[\"ghcr.io\"].username'")
+	cmd.Flags().StringVar(&res.toDir, "to-dir", res.toDir, "writes each decoded key to its own file in this directory using the layout of a secret volume mount")
+	cmd.Flags().BoolVar(&res.force, "force", res.force, "if true, replaces existing files when writing with --to-dir")
+	cmd.Flags().BoolVar(&res.unwrap, "unwrap", res.unwrap, "if true, detects and removes nested encodings (base64, base64url, gzip, zlib, JSON strings) from the values")
+	cmd.Flags().IntVar(&res.unwrapDepth, "unwrap-depth", defaultUnwrapDepth, "maximum number of nested encodings removed per value with --unwrap")
+	cmd.Flags().BoolVar(&res.clipboard, "clipboard", res.clipboard, "if true, copies a single decoded value to the clipboard instead of printing it")
+	cmd.Flags().DurationVar(&res.clipboardClear, "clipboard-clear", defaultClipboardClear, "clears the copied value from the clipboard after this duration unless it changed, 0 keeps it")
+	cmd.Flags().StringVar(&res.hash, "hash", res.hash, "outputs a digest per key instead of the decoded value: "+strings.Join(hashAlgorithmNames(), ", "))
+	cmd.Flags().StringVar(&res.hmacKeyFile, "hmac-key-file", res.hmacKeyFile, "keys the --hash digests with the exact bytes of this file using HMAC, implies --hash sha256")
+	cmd.Flags().BoolVar(&res.raw, "raw", res.raw, "if true, writes the exact bytes of a single decoded value without a trailing newline")
+	cmd.Flags().BoolVar(&res.details, "details", res.details, "if true, shows a type-aware structured view of the secret, e.g. certificate details for TLS secrets or decoded JWT claims")
+	cmd.Flags().StringVarP(&res.outputFormat, "output", "o", "text", "output format: text, json, yaml, env, dotenv, docker-env-file")
+	cmd.Flags().StringVar(&res.mask, "mask", res.mask, "hides the values and the passwords and tokens in structured views, the mode must be attached as in --mask=partial: "+strings.Join(maskModeNames(), ", "))
+	cmd.Flags().Lookup("mask").NoOptDefVal = string(MaskFull)
+	cmd.Flags().IntVar(&res.maskChars, "mask-chars", defaultMaskChars, "number of characters revealed at the start and end of values with --mask=partial")
+	cmd.Flags().StringVar(&res.registry, "registry", res.registry, "only show the credentials for this registry host in the structured view of docker config secrets, implies --details")
+	cmd.Flags().BoolVar(&res.browse, "browse", res.browse, "if true, opens a full-screen browser navigating contexts, namespaces, secrets and keys")
+	cmd.Flags().StringVar(&res.helmPart, "helm-part", res.helmPart, "print a single part of a helm release: "+strings.Join(helmPartNames(), ", "))
+
+	res.sourceFlags.addFlags(cmd)
+
+	// Shell completion is provided through kubectl's plugin completion instead of a completion subcommand
+	cmd.CompletionOptions.DisableDefaultCmd = true
+	cmd.AddCommand(newCmdCertScan(), newCmdDiff(), newCmdFindKeys(), newCmdGrep(), newCmdHelmDiff())
+	for _, sub := range cmd.Commands() {
+		viewShadowedSecret(sub)
+	}
 
 	// Add shell completion functions
-	_ = cmd.RegisterFlagCompletionFunc("namespace", getNamespaces)
+	_ = cmd.MarkFlagDirname("to-dir")
+	_ = cmd.RegisterFlagCompletionFunc("hash", cobra.FixedCompletions(hashAlgorithmNames(), cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("mask", cobra.FixedCompletions(maskModeNames(), cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("keys", getDataKeys)
+	_ = cmd.RegisterFlagCompletionFunc("exclude-keys", getDataKeys)
+	_ = cmd.RegisterFlagCompletionFunc("helm-part", cobra.FixedCompletions(helmPartNames(), cobra.ShellCompDirectiveNoFileComp))
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		switch len(args) {
-		case 0:
+		if len(args) == 0 {
 			return getSecrets(cmd, args, toComplete)
-		case 1:
-			return getDataKeys(cmd, args, toComplete)
-		default:
-			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
+		return getDataKeys(cmd, args, toComplete)
 	}
 
 	return cmd
 }
 
+// viewShadowedSecret keeps viewing secrets named like the subcommand as before it existed
+//
+// If a secret with the name of the subcommand exists, it's viewed with the
+// arguments as keys instead of running the subcommand. The arguments are
+// validated afterwards since they're keys of the secret in that case.
+// Manifests from stdin can't be read twice, so they're never looked up.
+func viewShadowedSecret(sub *cobra.Command) {
+	validateArgs, run := sub.Args, sub.RunE
+
+	sub.Args = cobra.ArbitraryArgs
+	sub.RunE = func(c *cobra.Command, args []string) error {
+		if source, ok := shadowedSecretSource(c); ok {
+			_, _ = fmt.Fprintf(c.ErrOrStderr(), shadowedSecretDescription+"\n", c.Name(), c.Name())
+
+			opts := &CommandOpts{
+				clipboardClear: defaultClipboardClear,
+				maskChars:      defaultMaskChars,
+				outputFormat:   "text",
+				source:         source,
+				unwrapDepth:    defaultUnwrapDepth,
+			}
+			opts.ParseArgs(append([]string{c.Name()}, args...))
+			return opts.Retrieve(c)
+		}
+
+		if validateArgs != nil {
+			if err := validateArgs(c, args); err != nil {
+				return err
+			}
+		}
+		return run(c, args)
+	}
+}
+
+// shadowedSecretSource returns the source holding a secret named like the command
+func shadowedSecretSource(cmd *cobra.Command) (SecretSource, bool) {
+	if filenames, _ := cmd.Flags().GetStringSlice("filename"); slices.Contains(filenames, stdinFilename) {
+		return nil, false
+	}
+
+	source, err := newSecretSourceFromFlags(cmd)
+	if err != nil {
+		return nil, false
+	}
+	if _, err := source.GetSecret(contextFromCommand(cmd), cmd.Name()); err != nil {
+		return nil, false
+	}
+
+	return source, true
+}
+
 // ParseArgs serializes the user supplied program arguments
 func (c *CommandOpts) ParseArgs(args []string) {
 	argLen := len(args)
 	if argLen >= 1 {
 		c.secretName = args[0]
 
-		if argLen == 2 {
+		if argLen >= 2 {
 			c.secretKey = args[1]
+			c.keys = append(c.keys, args[2:]...)
 		}
 	}
 }
 
 // Retrieve reads the kubeconfig and decodes the secret
 //
-// It executes kubectl to fetch secret data, handles user interaction
-// for secret selection, and outputs the decoded content in the
-// specified format.
+// It fetches the secret data from the configured backend, handles user
+// interaction for secret selection, and outputs the decoded content in
+// the specified format.
 func (c *CommandOpts) Retrieve(cmd *cobra.Command) error {
-	commandArgs := c.buildKubectlCommand(cmd)
-	output, err := c.executeKubectlCommand(commandArgs)
+	if c.browse {
+		return c.runBrowser(cmd)
+	}
+
+	source, err := c.secretSource(cmd)
 	if err != nil {
 		return err
 	}
 
-	secret, err := c.parseSecretResponse(output, cmd)
+	if c.selectsMultiple() {
+		return c.retrieveMultiple(cmd, source)
+	}
+
+	secret, err := c.fetchSecret(cmd, source)
 	if err != nil {
 		return err
 	}
 
-	if c.quiet {
-		return ProcessSecretWithOptions(cmd.OutOrStdout(), io.Discard, cmd.InOrStdin(), secret, c.secretKey, c.decodeAll, c.outputFormat)
+	if c.helmPart != "" {
+		if c.hash != "" || c.hmacKeyFile != "" {
+			return fmt.Errorf("--helm-part: %w", ErrHashUnsupported)
+		}
+		return ProcessHelmRelease(cmd.OutOrStdout(), secret, HelmPart(c.helmPart), c.outputFormat)
 	}
 
-	return ProcessSecretWithOptions(cmd.OutOrStdout(), cmd.OutOrStderr(), cmd.InOrStdin(), secret, c.secretKey, c.decodeAll, c.outputFormat)
-}
-
-// buildKubectlCommand builds the kubectl command arguments
-func (c *CommandOpts) buildKubectlCommand(cmd *cobra.Command) []string {
-	nsOverride, _ := cmd.Flags().GetString("namespace")
-	ctxOverride, _ := cmd.Flags().GetString("context")
-	kubeConfigOverride, _ := cmd.Flags().GetString("kubeconfig")
-	impersonateOverride, _ := cmd.Flags().GetString("as")
-	impersonateGroupOverride, _ := cmd.Flags().GetString("as-group")
+	opts := c.processOptions()
+	if err := c.loadHMACKey(&opts); err != nil {
+		return err
+	}
 
-	commandArgs := []string{"get", "secret", "-o", "json"}
-	if c.secretName != "" {
-		commandArgs = []string{"get", "secret", c.secretName, "-o", "json"}
+	if c.clipboard {
+		if opts.Hash != "" {
+			return fmt.Errorf("--clipboard: %w", ErrHashUnsupported)
+		}
+		if opts.Clipboard, err = newClipboard(cmd.ErrOrStderr()); err != nil {
+			return err
+		}
 	}
 
-	if nsOverride != "" {
-		commandArgs = append(commandArgs, "-n", nsOverride)
+	if c.quiet {
+		return ProcessSecretWithOptions(cmd.OutOrStdout(), io.Discard, cmd.InOrStdin(), secret, opts)
 	}
 
-	if ctxOverride != "" {
-		commandArgs = append(commandArgs, "--context", ctxOverride)
+	return ProcessSecretWithOptions(cmd.OutOrStdout(), cmd.OutOrStderr(), cmd.InOrStdin(), secret, opts)
+}
+
+// processOptions returns the processing options selected by the user
+func (c *CommandOpts) processOptions() ProcessOptions {
+	return ProcessOptions{
+		ClipboardClear: c.clipboardClear,
+		DecodeAll:      c.decodeAll,
+		Details:        c.details || c.registry != "",
+		ExcludeKeys:    c.excludeKeys,
+		Force:          c.force,
+		Hash:           c.hash,
+		Mask:           Masking{Chars: c.maskChars, Mode: MaskMode(c.mask)},
+		OutputFormat:   c.outputFormat,
+		Query:          c.query,
+		Raw:            c.raw,
+		Registry:       c.registry,
+		SecretKeys:     c.selectedKeys(),
+		ToDir:          c.toDir,
+		Unwrap:         c.unwrap,
+		UnwrapDepth:    c.unwrapDepth,
 	}
+}
 
-	if kubeConfigOverride != "" {
-		commandArgs = append(commandArgs, "--kubeconfig", kubeConfigOverride)
+// loadHMACKey reads the HMAC key file into the options, defaulting the hash to sha256
+func (c *CommandOpts) loadHMACKey(opts *ProcessOptions) error {
+	if c.hmacKeyFile == "" {
+		return nil
 	}
 
-	if impersonateOverride != "" {
-		commandArgs = append(commandArgs, "--as", impersonateOverride)
+	key, err := readHMACKey(c.hmacKeyFile)
+	if err != nil {
+		return err
 	}
 
-	if impersonateGroupOverride != "" {
-		commandArgs = append(commandArgs, "--as-group", impersonateGroupOverride)
+	opts.HMACKey = key
+	if opts.Hash == "" {
+		opts.Hash = "sha256"
 	}
 
-	return commandArgs
+	return nil
 }
 
-// executeKubectlCommand executes the kubectl command and returns the output
-func (c *CommandOpts) executeKubectlCommand(commandArgs []string) ([]byte, error) {
-	var res, cmdErr bytes.Buffer
-
-	out := exec.Command("kubectl", commandArgs...)
-	out.Stdout = &res
-	out.Stderr = &cmdErr
-	err := out.Run()
-	if err != nil {
-		if cmdErr.Len() > 0 {
-			return nil, fmt.Errorf("%sError: kubectl command failed: %w", cmdErr.String(), err)
-		}
-		return nil, fmt.Errorf("kubectl command failed: %w", err)
+// secretSource returns the configured secret source or creates one from the command flags
+func (c *CommandOpts) secretSource(cmd *cobra.Command) (SecretSource, error) {
+	if c.source != nil {
+		return c.source, nil
 	}
 
-	return res.Bytes(), nil
+	return newSecretSourceFromFlags(cmd)
 }
 
-// parseSecretResponse parses the kubectl output and handles secret selection
-func (c *CommandOpts) parseSecretResponse(output []byte, cmd *cobra.Command) (Secret, error) {
-	var secret Secret
+// fetchSecret retrieves the requested secret or prompts for one if no name was provided
+func (c *CommandOpts) fetchSecret(cmd *cobra.Command, source SecretSource) (Secret, error) {
 	if c.secretName == "" {
-		return c.handleSecretSelection(output, cmd)
-	}
-
-	if err := json.Unmarshal(output, &secret); err != nil {
-		return secret, fmt.Errorf("failed to parse kubectl output as secret: %w", err)
+		secretList, err := source.ListSecrets(contextFromCommand(cmd), ListOptions{})
+		if err != nil {
+			return Secret{}, err
+		}
+		return c.handleSecretSelection(secretList, cmd)
 	}
 
-	return secret, nil
+	return source.GetSecret(contextFromCommand(cmd), c.secretName)
 }
 
 // handleSecretSelection handles the interactive selection of a secret from a list
-func (c *CommandOpts) handleSecretSelection(output []byte, cmd *cobra.Command) (Secret, error) {
-	var secretList SecretList
-	if err := json.Unmarshal(output, &secretList); err != nil {
-		return Secret{}, fmt.Errorf("failed to parse kubectl output as secret list: %w", err)
-	}
-
+func (c *CommandOpts) handleSecretSelection(secretList SecretList, cmd *cobra.Command) (Secret, error) {
 	// Since we don't query valid namespaces, we'll avoid prompting the user to select a secret if we didn't retrieve any secrets
 	if len(secretList.Items) == 0 {
 		return Secret{}, ErrNoSecretFound
@@ -269,13 +505,46 @@ func (c *CommandOpts) handleSecretSelection(output []byte, cmd *cobra.Command) (
 	return secretMap[c.secretName], nil
 }
 
+// ProcessOptions holds the settings controlling how a secret is processed and rendered
+//
+// SecretKey and SecretKeys select the keys to view, a single selected key is
+// viewed on its own and multiple ones are rendered together. ExcludeKeys
+// leaves keys out of any selection.
+type ProcessOptions struct {
+	Clipboard      Clipboard
+	ClipboardClear time.Duration
+	DecodeAll      bool
+	Details        bool
+	ExcludeKeys    []string
+	Force          bool
+	HMACKey        []byte
+	Hash           string
+	Mask           Masking
+	OutputFormat   string
+	Query          string
+	Raw            bool
+	Registry       string
+	RevealKeys     []string
+	SecretKey      string
+	SecretKeys     []string
+	ToDir          string
+	Unwrap         bool
+	UnwrapDepth    int
+}
+
 // ProcessSecret takes the secret and user input to determine the output
 func ProcessSecret(outWriter, errWriter io.Writer, inputReader io.Reader, secret Secret, secretKey string, decodeAll bool) error {
-	return ProcessSecretWithOptions(outWriter, errWriter, inputReader, secret, secretKey, decodeAll, "text")
+	return ProcessSecretWithOptions(outWriter, errWriter, inputReader, secret, ProcessOptions{
+		DecodeAll:    decodeAll,
+		OutputFormat: "text",
+		SecretKey:    secretKey,
+	})
 }
 
 // decodeAllData decodes all data in the secret and returns sorted key-value pairs
-func decodeAllData(secret Secret, data SecretData) ([]KeyValue, error) {
+//
+// With a query, keys whose value doesn't match are left out.
+func decodeAllData(secret Secret, data SecretData, opts ProcessOptions) ([]KeyValue, error) {
 	var keys []string
 	for k := range data {
 		keys = append(keys, k)
@@ -283,97 +552,212 @@ func decodeAllData(secret Secret, data SecretData) ([]KeyValue, error) {
 	sort.Strings(keys)
 	var decodedData []KeyValue
 	for _, k := range keys {
-		v := data[k]
-		s, err := secret.Decode(v)
+		kv, err := decodeKeyValue(secret, k, data[k], opts)
+		if opts.Query != "" && errors.Is(err, ErrQueryNoMatch) {
+			continue
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode key %s: %w", k, err)
+			return nil, err
 		}
-		decodedData = append(decodedData, KeyValue{Key: k, Value: s})
+		decodedData = append(decodedData, kv)
+	}
+	if opts.Query != "" && len(decodedData) == 0 {
+		return nil, fmt.Errorf("%w in any key: %s", ErrQueryNoMatch, opts.Query)
 	}
 	return decodedData, nil
 }
 
+// decodeKeyValue decodes a single value and applies the value transformations selected in the options
+func decodeKeyValue(secret Secret, key, value string, opts ProcessOptions) (KeyValue, error) {
+	s, err := secret.Decode(value)
+	if err != nil {
+		return KeyValue{}, fmt.Errorf("failed to decode key %s: %w", key, err)
+	}
+
+	var layers []string
+	if opts.Unwrap {
+		s, layers = unwrapValue(s, opts.UnwrapDepth)
+	}
+
+	if opts.Query != "" {
+		query, err := parseQuery(opts.Query)
+		if err != nil {
+			return KeyValue{}, err
+		}
+		result, err := query.apply(s)
+		if err != nil {
+			return KeyValue{}, fmt.Errorf("%w in key %s: %s", err, key, opts.Query)
+		}
+		if s, err = formatQueryResult(result, opts.OutputFormat); err != nil {
+			return KeyValue{}, fmt.Errorf("failed to render query result of key %s: %w", key, err)
+		}
+	}
+
+	if opts.Hash != "" {
+		// Decode pretty-prints docker configs and inflates helm releases, so
+		// the stored bytes are hashed unless the value was transformed on purpose
+		raw := []byte(s)
+		if !opts.Unwrap && opts.Query == "" {
+			if raw, err = base64.StdEncoding.DecodeString(value); err != nil {
+				return KeyValue{}, fmt.Errorf("failed to decode key %s: %w", key, err)
+			}
+		}
+
+		digest, err := digestValue(raw, opts.Hash, opts.HMACKey)
+		if err != nil {
+			return KeyValue{}, err
+		}
+		return KeyValue{Key: key, Layers: layers, Value: digest}, nil
+	}
+
+	kv := newKeyValue(key, s)
+	kv.Layers = layers
+
+	return kv, nil
+}
+
 // ProcessSecretWithOptions takes the secret and user input with full options
-func ProcessSecretWithOptions(outWriter, errWriter io.Writer, inputReader io.Reader, secret Secret, secretKey string, decodeAll bool, outputFormat string) error {
+func ProcessSecretWithOptions(outWriter, errWriter io.Writer, inputReader io.Reader, secret Secret, opts ProcessOptions) error {
 	data := secret.Data
 	if len(data) == 0 {
 		return ErrSecretEmpty
 	}
 
+	if err := opts.Mask.validate(); err != nil {
+		return err
+	}
+
+	if err := validateHash(opts.Hash); err != nil {
+		return err
+	}
+	if err := opts.validateHashOptions(); err != nil {
+		return err
+	}
+
+	// digests don't reveal the values, masking them would hide the only output
+	if opts.Hash != "" {
+		opts.Mask = Masking{}
+	}
+
+	if opts.Details {
+		details, err := secretDetails(secret, DetailsOptions{
+			Mask:     opts.Mask,
+			Now:      time.Now(),
+			Registry: opts.Registry,
+		})
+		if err != nil {
+			return err
+		}
+		return outputFormattedSecret(outWriter, secret, details, opts.OutputFormat)
+	}
+
+	// A single selected key is viewed on its own, multiple ones are rendered together
+	if keys := opts.keySelection(); len(keys) == 1 {
+		opts.SecretKey, opts.SecretKeys = keys[0], nil
+	} else {
+		opts.SecretKey, opts.SecretKeys = "", keys
+	}
+
+	data, missing := filterSecretData(data, opts.SecretKeys, opts.ExcludeKeys)
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrSecretKeyNotFound, strings.Join(missing, ", "))
+	}
+	if len(data) == 0 {
+		return ErrAllKeysExcluded
+	}
+
+	secretKey, decodeAll := opts.SecretKey, opts.DecodeAll || len(opts.SecretKeys) > 0
+
 	var keys []string
 	for k := range data {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 
+	if opts.Raw {
+		switch {
+		case secretKey != "" && !decodeAll:
+			return writeRaw(outWriter, secret, secretKey, opts)
+		case len(keys) == 1:
+			return writeRaw(outWriter, secret, keys[0], opts)
+		default:
+			return ErrRawRequiresSingleKey
+		}
+	}
+
+	if opts.ToDir != "" {
+		return writeSecretToDir(errWriter, secret, keys, opts)
+	}
+
+	// Without a key the picker below selects a single one and calls back into here
+	if opts.Clipboard != nil {
+		switch {
+		case decodeAll:
+			return ErrClipboardRequiresSingleKey
+		case secretKey != "":
+			return writeClipboard(errWriter, secret, secretKey, opts)
+		case len(keys) == 1:
+			return writeClipboard(errWriter, secret, keys[0], opts)
+		}
+	}
+
 	if decodeAll {
-		decodedData, err := decodeAllData(secret, data)
+		decodedData, err := decodeAllData(secret, data, opts)
 		if err != nil {
 			return err
 		}
-		return outputFormattedSecret(outWriter, secret, decodedData, outputFormat)
+		return outputDecodedData(outWriter, errWriter, secret, decodedData, opts)
 	} else if len(data) == 1 {
 		if _, err := fmt.Fprintf(errWriter, singleKeyDescription+"\n", keys[0]); err != nil {
 			return fmt.Errorf("failed to write to stderr: %w", err)
 		}
-		decodedData, err := decodeAllData(secret, data)
+		decodedData, err := decodeAllData(secret, data, opts)
 		if err != nil {
 			return err
 		}
-		return outputFormattedSecret(outWriter, secret, decodedData, outputFormat)
+		return outputDecodedData(outWriter, errWriter, secret, decodedData, opts)
 	} else if secretKey != "" {
 		if v, ok := data[secretKey]; ok {
-			s, err := secret.Decode(v)
+			kv, err := decodeKeyValue(secret, secretKey, v, opts)
 			if err != nil {
-				return fmt.Errorf("failed to decode key %s: %w", secretKey, err)
-			}
-			if outputFormat == "text" {
-				if _, err := fmt.Fprintf(outWriter, "%s\n", s); err != nil {
-					return fmt.Errorf("failed to write output: %w", err)
-				}
-			} else {
-				decodedData := []KeyValue{
-					{
-						Key:   secretKey,
-						Value: s,
-					},
-				}
-				return outputFormattedSecret(outWriter, secret, decodedData, outputFormat)
+				return err
 			}
+			return outputDecodedData(outWriter, errWriter, secret, []KeyValue{kv}, opts)
 		} else {
 			return ErrSecretKeyNotFound
 		}
 	} else {
-		opts := make([]string, len(keys)+1)
-		opts[0] = "all"
-		copy(opts[1:], keys)
-
-		var selection string
-		err := huh.NewForm(
-			huh.NewGroup(
-				huh.NewSelect[string]().
-					Title(secretTitle).
-					Description(fmt.Sprintf(secretDescription, len(data), secret.Metadata.Name)).
-					Options(huh.NewOptions(opts...)...).
-					Value(&selection),
-			),
-		).WithProgramOptions(tea.WithInput(inputReader), tea.WithOutput(outWriter)).Run()
+		selection, err := selectKeys(outWriter, inputReader, secret, keys, opts.Clipboard != nil)
 		if err != nil {
 			return err
 		}
 
-		if selection == "all" {
-			decodeAll = true
+		if len(selection) == 1 {
+			opts.SecretKey = selection[0]
+		} else {
+			opts.SecretKeys = selection
 		}
 
-		return ProcessSecretWithOptions(outWriter, errWriter, inputReader, secret, selection, decodeAll, outputFormat)
+		if opts.Mask.Mode != MaskNone {
+			if opts.RevealKeys, err = selectRevealKeys(outWriter, inputReader, selection); err != nil {
+				return err
+			}
+		}
+
+		return ProcessSecretWithOptions(outWriter, errWriter, inputReader, secret, opts)
 	}
+}
 
-	return nil
+// textRenderer is implemented by structured views that provide their own text output
+type textRenderer interface {
+	renderText(outWriter io.Writer) error
 }
 
 // buildOutputMap builds the common output structure for JSON/YAML formats
-func buildOutputMap(secret Secret, sortedData []KeyValue) map[string]any {
+//
+// The data is either the sorted list of decoded key-value pairs or a
+// type-aware structured view of the secret.
+func buildOutputMap(secret Secret, sortedData any) map[string]any {
 	return map[string]any{
 		"name":      secret.Metadata.Name,
 		"namespace": secret.Metadata.Namespace,
@@ -382,48 +766,106 @@ func buildOutputMap(secret Secret, sortedData []KeyValue) map[string]any {
 	}
 }
 
+// outputDecodedData masks the decoded values and outputs them in the specified format
+//
+// Structured formats carry the unwrap chain of each value in the output, for
+// text it's reported on the error writer to keep the output pipeable.
+func outputDecodedData(outWriter, errWriter io.Writer, secret Secret, decodedData []KeyValue, opts ProcessOptions) error {
+	if opts.OutputFormat != "json" && opts.OutputFormat != "yaml" {
+		if err := reportLayers(errWriter, decodedData); err != nil {
+			return err
+		}
+	}
+
+	decodedData = maskKeyValues(decodedData, opts.Mask, opts.RevealKeys)
+
+	return outputFormattedSecret(outWriter, secret, decodedData, opts.OutputFormat)
+}
+
 // outputFormattedSecret outputs the secret in the specified format
-func outputFormattedSecret(outWriter io.Writer, secret Secret, decodedData []KeyValue, outputFormat string) error {
+func outputFormattedSecret(outWriter io.Writer, secret Secret, decodedData any, outputFormat string) error {
 	switch outputFormat {
 	case "json":
 		return outputJSON(outWriter, secret, decodedData)
 	case "yaml":
 		return outputYAML(outWriter, secret, decodedData)
+	}
+
+	if isEnvFormat(outputFormat) {
+		data, ok := decodedData.([]KeyValue)
+		if !ok {
+			return fmt.Errorf("output format %s is only supported for decoded values", outputFormat)
+		}
+		return outputEnvFormat(outWriter, data, outputFormat)
+	}
+
+	switch data := decodedData.(type) {
+	case []KeyValue:
+		return outputText(outWriter, data)
+	case textRenderer:
+		return data.renderText(outWriter)
 	default:
-		return outputText(outWriter, decodedData)
+		return fmt.Errorf("unsupported text output for %T", decodedData)
 	}
 }
 
 // outputJSON outputs secret data as JSON
-func outputJSON(outWriter io.Writer, secret Secret, decodedData []KeyValue) error {
-	output := buildOutputMap(secret, decodedData)
+func outputJSON(outWriter io.Writer, secret Secret, decodedData any) error {
+	return writeJSON(outWriter, buildOutputMap(secret, decodedData))
+}
+
+// outputYAML outputs secret data as YAML
+func outputYAML(outWriter io.Writer, secret Secret, decodedData any) error {
+	return writeYAML(outWriter, buildOutputMap(secret, decodedData))
+}
+
+// writeJSON encodes the output as indented JSON
+func writeJSON(outWriter io.Writer, output any) error {
 	encoder := json.NewEncoder(outWriter)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(output)
 }
 
-// outputYAML outputs secret data as YAML
-func outputYAML(outWriter io.Writer, secret Secret, decodedData []KeyValue) error {
-	output := buildOutputMap(secret, decodedData)
+// writeYAML encodes the output as YAML
+func writeYAML(outWriter io.Writer, output any) error {
 	return yaml.NewEncoder(outWriter).Encode(output)
 }
 
 // outputText outputs secret data as plain text
+//
+// Binary values are rendered as a hexdump instead of being written to the terminal.
 func outputText(outWriter io.Writer, sortedData []KeyValue) error {
+	return writeText(outWriter, sortedData, len(sortedData) > 1)
+}
+
+// writeText outputs the values as plain text, prefixed with their key if requested
+func writeText(outWriter io.Writer, sortedData []KeyValue, withKeys bool) error {
 	var format string
 
-	if len(sortedData) == 1 {
-		format = "%s\n"
-	} else {
+	if withKeys {
 		format = "%s='%s'\n"
+	} else {
+		format = "%s\n"
 	}
 
 	for _, kv := range sortedData {
+		if kv.Encoding == encodingBase64 {
+			if withKeys {
+				if _, err := fmt.Fprintf(outWriter, "%s (binary):\n", kv.Key); err != nil {
+					return fmt.Errorf("failed to write output: %w", err)
+				}
+			}
+			if err := writeHexdump(outWriter, kv); err != nil {
+				return err
+			}
+			continue
+		}
+
 		var args []any
-		if len(sortedData) == 1 {
-			args = []any{kv.Value}
-		} else {
+		if withKeys {
 			args = []any{kv.Key, kv.Value}
+		} else {
+			args = []any{kv.Value}
 		}
 		if _, err := fmt.Fprintf(outWriter, format, args...); err != nil {
 			return fmt.Errorf("failed to write output: %w", err)
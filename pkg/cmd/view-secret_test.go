@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -37,8 +39,9 @@ func TestParseArgs(t *testing.T) {
 		args     []string
 		wantOpts CommandOpts
 	}{
-		"one arg":  {opts, []string{"test"}, CommandOpts{secretName: "test"}},
-		"two args": {opts, []string{"test", "key"}, CommandOpts{secretName: "test", secretKey: "key"}},
+		"one arg":    {opts, []string{"test"}, CommandOpts{secretName: "test"}},
+		"two args":   {opts, []string{"test", "key"}, CommandOpts{secretName: "test", secretKey: "key"}},
+		"three args": {opts, []string{"test", "key", "other"}, CommandOpts{secretName: "test", secretKey: "key", keys: []string{"other"}}},
 	}
 
 	for name, test := range tests {
@@ -46,15 +49,26 @@ func TestParseArgs(t *testing.T) {
 			t.Parallel()
 
 			test.opts.ParseArgs(test.args)
-			got := test.opts
-			if got != test.wantOpts {
-				t.Errorf("got %v, want %v", got, test.wantOpts)
-			}
+			assert.Equal(t, test.wantOpts, test.opts)
 		})
 	}
 }
 
+// clusterTestEnv enables the tests running against the kind cluster seeded by hack/kind-bootstrap.sh
+const clusterTestEnv = "KVS_TEST_CLUSTER"
+
+// requireCluster skips the test unless the kind cluster is available
+func requireCluster(t *testing.T) {
+	t.Helper()
+
+	if os.Getenv(clusterTestEnv) == "" {
+		t.Skipf("set %s=1 to run against the kind cluster created by make bootstrap", clusterTestEnv)
+	}
+}
+
 func TestNewCmdViewSecret(t *testing.T) {
+	requireCluster(t)
+
 	tests := map[string]struct {
 		args     []string
 		feedkeys string
@@ -73,7 +87,7 @@ func TestNewCmdViewSecret(t *testing.T) {
 		// make bootstrap sources 2 test secrets in the default namespace, select the first one and print all values
 		"interactive":                       {args: []string{"--all"}, feedkeys: "\r", want: `key1='value1'\nkey2='value2'`},
 		"interactive custom ns (no secret)": {args: []string{"--namespace", "empty"}, wantErr: ErrNoSecretFound},
-		"invalid arg count":                 {args: []string{"a", "b", "c"}, wantErr: errors.New("accepts between 0 and 2 arg(s), received 3")},
+		"multiple keys":                     {args: []string{"test", "key1", "key2"}, want: `key1='value1'\nkey2='value2'`},
 		"quiet":                             {args: []string{"test2", "--quiet"}, want: `value1`},
 		"unknown flag":                      {args: []string{"--version"}, wantErr: errors.New("unknown flag: --version")},
 	}
@@ -213,6 +227,19 @@ func TestProcessSecret(t *testing.T) {
 			nil,
 			"\x1b[B\x1b[B\r", // navigate to TEST_PASSWORD and select
 		},
+		"view-secret <secret> select multiple keys": {
+			secret,
+			Opaque,
+			[]string{
+				"TEST_PASSWORD='secret\n'",
+				"TEST_PASSWORD_2='verysecret\n'",
+			},
+			[]string{},
+			"",
+			false,
+			nil,
+			"\x1b[B\x1b[Bx\x1b[Bx\r", // navigate to TEST_PASSWORD, toggle it and TEST_PASSWORD_2
+		},
 	}
 
 	for name, test := range tests {
@@ -415,3 +442,83 @@ func TestCompletionFlagRegistration(t *testing.T) {
 	// Check that ValidArgsFunction is set
 	assert.NotNil(t, cmd.ValidArgsFunction, "ValidArgsFunction should be set")
 }
+
+func TestSubcommandNameAsSecret(t *testing.T) {
+	reserved := []string{"cert-scan", "diff", "find-keys", "grep", "helm-diff"}
+
+	var subcommands []string
+	for _, c := range NewCmdViewSecret().Commands() {
+		subcommands = append(subcommands, c.Name())
+	}
+	assert.ElementsMatch(t, reserved, subcommands, "update the reserved secret names in the README")
+
+	for _, name := range append(reserved, "help") {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewCmdViewSecret()
+			outBuf := bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(fmt.Sprintf("kind: Secret\nmetadata:\n  name: %s\nstringData:\n  key: value-of-%[1]s\n", name)))
+			cmd.SetArgs([]string{"-f", "-", "-q", "--", name, "key"})
+
+			assert.NoError(t, cmd.Execute())
+			assert.Equal(t, "value-of-"+name+"\n", outBuf.String())
+		})
+	}
+}
+
+func TestShadowedSecretFallback(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "secrets.yaml")
+	assert.NoError(t, os.WriteFile(manifest, []byte(`kind: Secret
+metadata:
+  name: diff
+stringData:
+  password: s3cret
+  username: app
+`), 0o600))
+
+	tests := map[string]struct {
+		args       []string
+		wantStdOut string
+		wantStdErr string
+		wantErr    string
+	}{
+		"secret named like the subcommand": {
+			args:       []string{"diff", "password", "-f", manifest},
+			wantStdOut: "s3cret\n",
+			wantStdErr: "Viewing secret \"diff\" which shadows the diff subcommand\n",
+		},
+		"keys beyond the subcommand arguments": {
+			args:       []string{"diff", "password", "username", "-f", manifest},
+			wantStdOut: "password='s3cret'\nusername='app'\n",
+			wantStdErr: "Viewing secret \"diff\" which shadows the diff subcommand\n",
+		},
+		"subcommand without such a secret": {
+			args:    []string{"find-keys", "a", "b", "-f", manifest},
+			wantErr: "accepts 1 arg(s), received 2",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewCmdViewSecret()
+			outBuf, errBuf := bytes.Buffer{}, bytes.Buffer{}
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&errBuf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStdOut, outBuf.String())
+			assert.Equal(t, tt.wantStdErr, errBuf.String())
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// volumeDataDir is the symlink pointing to the current payload directory of a secret volume
+	volumeDataDir = "..data"
+
+	// volumeFileMode is the permission of the files written to the directory
+	volumeFileMode = 0o600
+)
+
+var (
+	// ErrInvalidVolumeKey is thrown if a key can't be used as a file name in a secret volume
+	ErrInvalidVolumeKey = errors.New("key can't be used as a file name")
+
+	// ErrVolumeTargetExists is thrown if files would be overwritten without --force
+	ErrVolumeTargetExists = errors.New("refusing to overwrite existing files, use --force to replace them")
+
+	// ErrVolumeTargetNotFile is thrown if an entry to replace is neither a symlink nor a regular file, even with --force
+	ErrVolumeTargetNotFile = errors.New("refusing to replace entries which aren't files or symlinks")
+)
+
+// writeVolumeDir writes the files to the directory using the layout kubelet produces for secret volumes
+//
+// The payload is written to a timestamped directory `..<timestamp>` and
+// `..data` is a symlink to it. Every key is a symlink `<key> -> ..data/<key>`,
+// so the content of all keys is swapped atomically by replacing `..data`.
+// Without force, nothing is written if any of the entries already exists.
+// Entries which aren't symlinks or regular files, e.g. directories, are
+// never replaced.
+func writeVolumeDir(dir string, files map[string][]byte, force bool, now time.Time, errWriter io.Writer) error {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		if err := validateVolumeKey(key); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var existing, notFiles []string
+	for _, name := range append([]string{volumeDataDir}, keys...) {
+		info, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		existing = append(existing, name)
+		if !replaceableVolumeEntry(name, info) {
+			notFiles = append(notFiles, name)
+		}
+	}
+	if len(notFiles) > 0 {
+		return fmt.Errorf("%w: %s", ErrVolumeTargetNotFile, strings.Join(notFiles, ", "))
+	}
+	if len(existing) > 0 && !force {
+		return fmt.Errorf("%w: %s", ErrVolumeTargetExists, strings.Join(existing, ", "))
+	}
+
+	oldPayload, _ := os.Readlink(filepath.Join(dir, volumeDataDir))
+
+	payload, err := os.MkdirTemp(dir, now.UTC().Format("..2006_01_02_15_04_05."))
+	if err != nil {
+		return fmt.Errorf("failed to create payload directory: %w", err)
+	}
+	for _, key := range keys {
+		if err := os.WriteFile(filepath.Join(payload, key), files[key], volumeFileMode); err != nil {
+			return fmt.Errorf("failed to write key %s: %w", key, err)
+		}
+	}
+
+	// swap the payload atomically by renaming a new symlink over the old one
+	tmpLink := filepath.Join(dir, volumeDataDir+"_tmp")
+	_ = os.Remove(tmpLink)
+	if err := os.Symlink(filepath.Base(payload), tmpLink); err != nil {
+		return fmt.Errorf("failed to link payload directory: %w", err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, volumeDataDir)); err != nil {
+		return fmt.Errorf("failed to link payload directory: %w", err)
+	}
+
+	for _, key := range keys {
+		if err := linkVolumeKey(dir, key); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(errWriter, "Wrote %s (%d bytes)\n", filepath.Join(dir, key), len(files[key])); err != nil {
+			return fmt.Errorf("failed to write to stderr: %w", err)
+		}
+	}
+
+	return removeStaleVolumeEntries(dir, oldPayload, files)
+}
+
+// validateVolumeKey rejects keys which can't be files or would clash with the volume internals
+func validateVolumeKey(key string) error {
+	if key == "" || key == "." || strings.HasPrefix(key, "..") || strings.ContainsAny(key, `/\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidVolumeKey, key)
+	}
+	return nil
+}
+
+// replaceableVolumeEntry reports whether an existing entry can be replaced
+//
+// `..data` must be the symlink of a previous run, keys may also be regular files.
+func replaceableVolumeEntry(name string, info os.FileInfo) bool {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return true
+	}
+	return name != volumeDataDir && info.Mode().IsRegular()
+}
+
+// linkVolumeKey points the entry of a key into the current payload, replacing an existing file or symlink
+func linkVolumeKey(dir, key string) error {
+	path := filepath.Join(dir, key)
+	target := filepath.Join(volumeDataDir, key)
+
+	if current, err := os.Readlink(path); err == nil && current == target {
+		return nil
+	}
+
+	info, err := os.Lstat(path)
+	if err == nil && !replaceableVolumeEntry(key, info) {
+		return fmt.Errorf("%w: %s", ErrVolumeTargetNotFile, path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	if err := os.Symlink(target, path); err != nil {
+		return fmt.Errorf("failed to link key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// removeStaleVolumeEntries removes the previous payload and the links of keys which no longer exist
+func removeStaleVolumeEntries(dir, oldPayload string, files map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if _, ok := files[entry.Name()]; ok || entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		if target, err := os.Readlink(filepath.Join(dir, entry.Name())); err == nil && strings.HasPrefix(target, volumeDataDir+string(filepath.Separator)) {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return fmt.Errorf("failed to remove stale key %s: %w", entry.Name(), err)
+			}
+		}
+	}
+
+	if oldPayload != "" && strings.HasPrefix(oldPayload, "..") && !strings.ContainsAny(oldPayload, `/\`) {
+		if err := os.RemoveAll(filepath.Join(dir, oldPayload)); err != nil {
+			return fmt.Errorf("failed to remove previous payload: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeSecretToDir decodes the selected keys of the secret and writes them to the target directory
+//
+// All keys are written unless a single key was requested.
+func writeSecretToDir(errWriter io.Writer, secret Secret, keys []string, opts ProcessOptions) error {
+	if opts.SecretKey != "" && !opts.DecodeAll {
+		keys = []string{opts.SecretKey}
+	}
+
+	files := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		raw, err := decodeRawValue(secret, key, opts)
+		if err != nil {
+			return err
+		}
+		files[key] = raw
+	}
+
+	return writeVolumeDir(opts.ToDir, files, opts.Force, time.Now(), errWriter)
+}
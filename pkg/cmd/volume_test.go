@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readVolumeDir returns the content of all keys in a secret volume directory and asserts the layout
+func readVolumeDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	payload, err := os.Readlink(filepath.Join(dir, volumeDataDir))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(payload, "..2025_06_01_12_00_00."), payload)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(volumeDataDir, entry.Name()), target)
+
+		info, err := os.Stat(filepath.Join(dir, entry.Name()))
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(volumeFileMode), info.Mode().Perm())
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		assert.NoError(t, err)
+		files[entry.Name()] = string(content)
+	}
+
+	return files
+}
+
+func TestWriteVolumeDir(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	dir := filepath.Join(t.TempDir(), "secret")
+
+	var errBuf bytes.Buffer
+	err := writeVolumeDir(dir, map[string][]byte{
+		".dockerconfigjson": []byte("{}"),
+		"tls.crt":           []byte("cert"),
+		"password":          []byte("hunter2\n"),
+	}, false, now, &errBuf)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{".dockerconfigjson": "{}", "password": "hunter2\n", "tls.crt": "cert"}, readVolumeDir(t, dir))
+	assert.Equal(t, "Wrote "+filepath.Join(dir, ".dockerconfigjson")+" (2 bytes)\n"+
+		"Wrote "+filepath.Join(dir, "password")+" (8 bytes)\n"+
+		"Wrote "+filepath.Join(dir, "tls.crt")+" (4 bytes)\n", errBuf.String())
+
+	t.Run("refuses to overwrite", func(t *testing.T) {
+		err := writeVolumeDir(dir, map[string][]byte{"password": []byte("changed")}, false, now, &bytes.Buffer{})
+		assert.ErrorIs(t, err, ErrVolumeTargetExists)
+		assert.ErrorContains(t, err, "..data, password")
+		assert.Equal(t, "hunter2\n", readVolumeDir(t, dir)["password"])
+	})
+
+	t.Run("force replaces payload and removes stale keys", func(t *testing.T) {
+		oldPayload, err := os.Readlink(filepath.Join(dir, volumeDataDir))
+		assert.NoError(t, err)
+
+		err = writeVolumeDir(dir, map[string][]byte{"password": []byte("changed"), "username": []byte("admin")}, true, now, &bytes.Buffer{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "changed", "username": "admin"}, readVolumeDir(t, dir))
+
+		_, err = os.Stat(filepath.Join(dir, oldPayload))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("force replaces regular files", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("old"), 0o644))
+
+		err := writeVolumeDir(dir, map[string][]byte{"password": []byte("new")}, true, now, &bytes.Buffer{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "new"}, readVolumeDir(t, dir))
+	})
+
+	t.Run("force never replaces directories", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "password", "nested"), 0o755))
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, volumeDataDir), 0o755))
+
+		err := writeVolumeDir(dir, map[string][]byte{"password": []byte("new")}, true, now, &bytes.Buffer{})
+		assert.ErrorIs(t, err, ErrVolumeTargetNotFile)
+		assert.ErrorContains(t, err, "..data, password")
+
+		_, err = os.Stat(filepath.Join(dir, "password", "nested"))
+		assert.NoError(t, err)
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for _, key := range []string{"..data", "..hidden", ".", "a/b"} {
+			err := writeVolumeDir(t.TempDir(), map[string][]byte{key: nil}, false, now, &bytes.Buffer{})
+			assert.ErrorIs(t, err, ErrInvalidVolumeKey, key)
+		}
+	})
+}
+
+func TestProcessSecretToDir(t *testing.T) {
+	secret := Secret{
+		Data: SecretData{
+			"keystore.jks": base64.StdEncoding.EncodeToString([]byte{0xfe, 0xed, 0x00}),
+			"password":     base64.StdEncoding.EncodeToString([]byte("hunter2")),
+		},
+		Type: Opaque,
+	}
+
+	t.Run("all keys", func(t *testing.T) {
+		dir := t.TempDir()
+		var outBuf, errBuf bytes.Buffer
+		assert.NoError(t, ProcessSecretWithOptions(&outBuf, &errBuf, nil, secret, ProcessOptions{ToDir: dir}))
+		assert.Empty(t, outBuf.String())
+
+		content, err := os.ReadFile(filepath.Join(dir, "keystore.jks"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0xfe, 0xed, 0x00}, content)
+		assert.Contains(t, errBuf.String(), "password (7 bytes)")
+	})
+
+	t.Run("single key", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, ProcessSecretWithOptions(&bytes.Buffer{}, &bytes.Buffer{}, nil, secret, ProcessOptions{SecretKey: "password", ToDir: dir}))
+
+		_, err := os.Lstat(filepath.Join(dir, "keystore.jks"))
+		assert.True(t, os.IsNotExist(err))
+		content, err := os.ReadFile(filepath.Join(dir, "password"))
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", string(content))
+	})
+}